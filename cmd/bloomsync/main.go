@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"short-url/internal/cache"
+	"short-url/internal/config"
+	"short-url/internal/database"
+	"short-url/internal/service"
+	"sort"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 0, "number of short codes read per batch (default BLOOM_FILTER_SYNC_BATCH_SIZE)")
+	capacity := flag.Int("capacity", 0, "capacity of the rebuilt filter (default: current filter capacity, at least BLOOM_FILTER_CAPACITY)")
+	flag.Parse()
+
+	// 加载配置
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.BloomFilter.Backend == cache.BloomBackendMemory {
+		log.Fatalf("Bloom filter backend %q lives inside the server process and cannot be synced externally, use POST /api/v1/admin/bloom/rebuild instead", cfg.BloomFilter.Backend)
+	}
+
+	ctx := context.Background()
+
+	// 连接数据库
+	db, err := database.New(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// 连接Redis
+	redisClient := cache.NewRedisClient(&cfg.Redis, &cfg.Cache)
+	defer redisClient.Close()
+
+	if err := redisClient.Ping(ctx); err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	bloomFilter := cache.NewBloomFilter(redisClient, &cfg.BloomFilter)
+	if err := bloomFilter.Initialize(ctx); err != nil {
+		log.Fatalf("Failed to initialize bloom filter: %v", err)
+	}
+
+	if *batchSize <= 0 {
+		*batchSize = cfg.BloomFilter.SyncBatchSize
+	}
+
+	fmt.Printf("Rebuilding bloom filter %q (backend: %s)...\n", bloomFilter.Key(), bloomFilter.Backend())
+
+	opts := service.BloomSyncOptions{
+		BatchSize: *batchSize,
+		Capacity:  *capacity,
+		Progress: func(scanned int64) {
+			fmt.Printf("  %d short codes added\n", scanned)
+		},
+	}
+
+	result, err := service.RebuildBloomFilter(ctx, service.NewRepository(db), bloomFilter, opts)
+	if err != nil {
+		log.Fatalf("Failed to rebuild bloom filter: %v", err)
+	}
+
+	fmt.Printf("Bloom filter rebuilt: %d short codes, %d re-added by catch-up scans, took %s\n",
+		result.Scanned, result.CaughtUp, result.FinishedAt.Sub(result.StartedAt))
+
+	fmt.Println("BF.INFO:")
+	keys := make([]string, 0, len(result.Info))
+	for key := range result.Info {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %-26s %v\n", key, result.Info[key])
+	}
+}
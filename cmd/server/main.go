@@ -11,7 +11,11 @@ import (
 	"short-url/internal/config"
 	"short-url/internal/database"
 	"short-url/internal/handler"
+	"short-url/internal/health"
+	"short-url/internal/metrics"
+	"short-url/internal/ratelimit"
 	"short-url/internal/service"
+	"short-url/internal/tracing"
 	"short-url/pkg/logger"
 	"syscall"
 	"time"
@@ -38,25 +42,89 @@ func main() {
 		zap.Int("port", cfg.App.Port),
 	)
 
-	// 初始化数据库
-	db, err := database.New(&cfg.Database)
+	// 初始化链路追踪，需早于数据库和Redis客户端创建
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing, cfg.App.Env)
 	if err != nil {
-		zapLogger.Fatal("Failed to initialize database", zap.Error(err))
+		zapLogger.Fatal("Failed to initialize tracing", zap.Error(err))
 	}
-	defer db.Close()
+	zapLogger.Info("Tracing initialized", zap.String("exporter", cfg.Tracing.Exporter))
 
-	zapLogger.Info("Database connected successfully")
+	// 依赖健康检查，随各组件初始化注册
+	healthChecker := health.NewChecker(cfg.App.HealthCheckTimeout)
 
-	// 初始化Redis客户端
-	redisClient := cache.NewRedisClient(&cfg.Redis, &cfg.Cache)
-	defer redisClient.Close()
+	// Prometheus 指标，连接池和服务计数随各组件初始化注册
+	appMetrics := metrics.New()
 
-	// 测试Redis连接
-	if err := redisClient.Ping(context.Background()); err != nil {
-		zapLogger.Fatal("Failed to connect to Redis", zap.Error(err))
+	// 初始化存储后端
+	var repo service.ShortLinkStore
+	var apiKeyStore service.APIKeyStore
+	switch cfg.Storage.Driver {
+	case service.StoreDriverMemory:
+		memoryRepo := service.NewMemoryRepository()
+		repo, apiKeyStore = memoryRepo, memoryRepo
+		zapLogger.Warn("Using in-memory storage, data will be lost on restart")
+	case service.StoreDriverPostgres, "":
+		db, err := database.New(&cfg.Database)
+		if err != nil {
+			zapLogger.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer db.Close()
+
+		postgresRepo := service.NewRepository(db)
+		repo, apiKeyStore = postgresRepo, postgresRepo
+		healthChecker.Register("postgres", db.Health)
+		appMetrics.Register(metrics.NewDBPoolCollector(db.Pool))
+		zapLogger.Info("Database connected successfully")
+	default:
+		zapLogger.Fatal("Unknown storage driver", zap.String("driver", cfg.Storage.Driver))
+	}
+
+	// 初始化Redis客户端（缓存、访问计数和布隆过滤器都使用进程内实现时可不依赖Redis）
+	var redisClient *cache.RedisClient
+	if needsRedis(cfg) {
+		redisClient = cache.NewRedisClient(&cfg.Redis, &cfg.Cache)
+		defer redisClient.Close()
+
+		// 测试Redis连接
+		if err := redisClient.Ping(context.Background()); err != nil {
+			zapLogger.Fatal("Failed to connect to Redis", zap.Error(err))
+		}
+		healthChecker.Register("redis", redisClient.Ping)
+		appMetrics.Register(metrics.NewRedisPoolCollector(redisClient.GetClient()))
+
+		zapLogger.Info("Redis connected successfully")
+	} else {
+		zapLogger.Info("Running without Redis")
 	}
 
-	zapLogger.Info("Redis connected successfully")
+	// 初始化缓存
+	var linkCache cache.Cache
+	switch cfg.Cache.Driver {
+	case cache.CacheDriverMemory:
+		linkCache = cache.NewLRUCache(&cfg.Cache)
+		zapLogger.Info("Using in-process LRU cache", zap.Int("max_entries", cfg.Cache.MaxEntries))
+	case cache.CacheDriverRedis, "":
+		linkCache = redisClient
+		if cfg.Cache.L1Enabled {
+			tieredCache, err := cache.NewTieredCache(redisClient, &cfg.Cache)
+			if err != nil {
+				zapLogger.Fatal("Failed to create two-tier cache", zap.Error(err))
+			}
+			if err := tieredCache.Start(context.Background()); err != nil {
+				zapLogger.Fatal("Failed to subscribe to cache invalidation channel", zap.Error(err))
+			}
+			defer tieredCache.Close()
+
+			linkCache = tieredCache
+			zapLogger.Info("Using two-tier cache",
+				zap.Int("l1_max_entries", cfg.Cache.L1MaxEntries),
+				zap.Duration("l1_ttl", cfg.Cache.L1TTL),
+				zap.String("invalidation_channel", cfg.Cache.InvalidationChannel),
+			)
+		}
+	default:
+		zapLogger.Fatal("Unknown cache driver", zap.String("driver", cfg.Cache.Driver))
+	}
 
 	// 初始化布隆过滤器
 	bloomFilter := cache.NewBloomFilter(redisClient, &cfg.BloomFilter)
@@ -64,17 +132,66 @@ func main() {
 		zapLogger.Fatal("Failed to initialize bloom filter", zap.Error(err))
 	}
 
-	zapLogger.Info("Bloom filter initialized successfully")
+	healthChecker.Register("bloom_filter", func(ctx context.Context) error {
+		_, err := bloomFilter.Info(ctx)
+		return err
+	})
+
+	zapLogger.Info("Bloom filter initialized successfully", zap.String("backend", bloomFilter.Backend()))
+
+	// 初始化访问计数缓冲
+	var accessCounter service.AccessCounter
+	switch cfg.AccessCount.Backend {
+	case service.AccessCounterMemory:
+		accessCounter = service.NewMemoryAccessCounter()
+	case service.AccessCounterRedis, "":
+		accessCounter = service.NewRedisAccessCounter(redisClient)
+	default:
+		zapLogger.Fatal("Unknown access count backend", zap.String("backend", cfg.AccessCount.Backend))
+	}
+
+	// 初始化独立访客计数，依赖点击事件管道
+	var visitorCounter service.VisitorCounter
+	if cfg.Analytics.Enabled {
+		switch cfg.Analytics.VisitorBackend {
+		case service.VisitorCounterMemory:
+			visitorCounter = service.NewMemoryVisitorCounter(cfg.Analytics.VisitorTTL)
+		case service.VisitorCounterRedis, "":
+			visitorCounter = service.NewRedisVisitorCounter(redisClient, cfg.Analytics.VisitorTTL)
+		default:
+			zapLogger.Fatal("Unknown visitor counter backend", zap.String("backend", cfg.Analytics.VisitorBackend))
+		}
+	}
+
+	// 初始化限流器，Redis 限流出错时降级为进程内限流
+	var limiter ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		switch cfg.RateLimit.Backend {
+		case ratelimit.BackendMemory:
+			limiter = ratelimit.NewMemoryLimiter()
+		case ratelimit.BackendRedis, "":
+			limiter = ratelimit.NewFallbackLimiter(
+				ratelimit.NewRedisLimiter(redisClient),
+				ratelimit.NewMemoryLimiter(),
+				cfg.RateLimit.FallbackCooldown,
+				zapLogger,
+			)
+		default:
+			zapLogger.Fatal("Unknown rate limit backend", zap.String("backend", cfg.RateLimit.Backend))
+		}
+	}
 
 	// 初始化服务层
-	repo := service.NewRepository(db)
-	shortLinkService := service.NewShortLinkService(repo, redisClient, bloomFilter, cfg, zapLogger)
+	shortLinkService := service.NewShortLinkService(repo, linkCache, bloomFilter, accessCounter, visitorCounter, cfg, zapLogger)
+	shortLinkService.Start()
+	appMetrics.Register(metrics.NewServiceCollector(shortLinkService))
 
 	// 初始化HTTP处理器
-	httpHandler := handler.NewHandler(shortLinkService, zapLogger)
+	apiKeyService := service.NewAPIKeyService(apiKeyStore, cfg.App.AdminToken, cfg.Auth.CacheTTL)
+	httpHandler := handler.NewHandler(shortLinkService, apiKeyService, healthChecker, zapLogger)
 
 	// 设置路由
-	router := handler.SetupRoutes(httpHandler, zapLogger)
+	router := handler.SetupRoutes(httpHandler, appMetrics, limiter, cfg, zapLogger)
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -93,6 +210,24 @@ func main() {
 		}
 	}()
 
+	// 调试接口单独监听内部地址，pprof 的 CPU profile 和 trace 耗时较长，不设置写超时
+	var debugServer *http.Server
+	if cfg.Debug.Addr != "" {
+		debugServer = &http.Server{
+			Addr:        cfg.Debug.Addr,
+			Handler:     handler.SetupDebugRoutes(httpHandler, appMetrics, cfg, zapLogger),
+			ReadTimeout: 15 * time.Second,
+			IdleTimeout: 60 * time.Second,
+		}
+
+		go func() {
+			zapLogger.Info("Debug server starting", zap.String("address", debugServer.Addr))
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				zapLogger.Fatal("Failed to start debug server", zap.Error(err))
+			}
+		}()
+	}
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -100,6 +235,13 @@ func main() {
 
 	zapLogger.Info("Server shutting down...")
 
+	// 先让就绪检查失败，等待负载均衡摘除实例后再关闭监听
+	healthChecker.SetDraining()
+	if delay := cfg.App.ShutdownDrainDelay; delay > 0 {
+		zapLogger.Info("Draining before shutdown", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
 	// 优雅关闭
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -108,5 +250,40 @@ func main() {
 		zapLogger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	if debugServer != nil {
+		if err := debugServer.Shutdown(ctx); err != nil {
+			zapLogger.Error("Debug server forced to shutdown", zap.Error(err))
+		}
+	}
+
+	if err := shortLinkService.Shutdown(ctx); err != nil {
+		zapLogger.Error("Background workers did not stop in time", zap.Error(err))
+	}
+
+	// 后台任务退出后再刷新剩余的 span
+	if err := shutdownTracing(ctx); err != nil {
+		zapLogger.Error("Failed to flush traces", zap.Error(err))
+	}
+
 	zapLogger.Info("Server shutdown complete")
 }
+
+// needsRedis 判断当前配置是否需要连接Redis
+func needsRedis(cfg *config.Config) bool {
+	if cfg.Cache.Driver != cache.CacheDriverMemory || cfg.AccessCount.Backend != service.AccessCounterMemory {
+		return true
+	}
+	if cfg.Analytics.Enabled && cfg.Analytics.VisitorBackend != service.VisitorCounterMemory {
+		return true
+	}
+	if cfg.RateLimit.Enabled && cfg.RateLimit.Backend != ratelimit.BackendMemory {
+		return true
+	}
+
+	switch cfg.BloomFilter.Backend {
+	case cache.BloomBackendRedisBloom, cache.BloomBackendBitmap:
+		return true
+	default:
+		return false
+	}
+}
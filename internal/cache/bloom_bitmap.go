@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 位图后端的参数保存在 <key>:meta 哈希中，位数组保存在 <key> 中。
+// 完整装载标记是参数哈希的 populated 字段，与过滤器一起创建、替换和删除。
+// 所有读写都通过 Lua 脚本完成，脚本内读取参数，保证多实例间一致且原子。
+var (
+	bitmapReserveScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'capacity', ARGV[1], 'error_rate', ARGV[2], 'bits', ARGV[3], 'hashes', ARGV[4], 'items', 0)
+return 1
+`)
+
+	// 参数哈希丢失时按默认参数重新创建，不带装载标记；
+	// 位数组丢失而参数还在时，已写入的元素也已丢失，清除装载标记
+	bitmapAddScript = redis.NewScript(`
+local meta = redis.call('HMGET', KEYS[2], 'bits', 'hashes')
+if not meta[1] then
+	redis.call('HSET', KEYS[2], 'capacity', ARGV[1], 'error_rate', ARGV[2], 'bits', ARGV[3], 'hashes', ARGV[4], 'items', 0)
+	meta = {ARGV[3], ARGV[4]}
+elseif redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('HDEL', KEYS[2], 'populated')
+end
+local m = tonumber(meta[1])
+local k = tonumber(meta[2])
+local results = {}
+local added = 0
+for j = 5, #ARGV, 2 do
+	local h1 = tonumber(ARGV[j])
+	local h2 = tonumber(ARGV[j + 1])
+	local new = 0
+	for i = 0, k - 1 do
+		if redis.call('SETBIT', KEYS[1], (h1 + i * h2) % m, 1) == 0 then
+			new = 1
+		end
+	end
+	results[#results + 1] = new
+	added = added + new
+end
+if added > 0 then
+	redis.call('HINCRBY', KEYS[2], 'items', added)
+end
+return results
+`)
+
+	bitmapExistsScript = redis.NewScript(`
+local meta = redis.call('HMGET', KEYS[2], 'bits', 'hashes')
+local results = {}
+if not meta[1] then
+	for j = 1, #ARGV, 2 do
+		results[#results + 1] = 0
+	end
+	return results
+end
+local m = tonumber(meta[1])
+local k = tonumber(meta[2])
+for j = 1, #ARGV, 2 do
+	local h1 = tonumber(ARGV[j])
+	local h2 = tonumber(ARGV[j + 1])
+	local found = 1
+	for i = 0, k - 1 do
+		if redis.call('GETBIT', KEYS[1], (h1 + i * h2) % m) == 0 then
+			found = 0
+			break
+		end
+	end
+	results[#results + 1] = found
+end
+return results
+`)
+
+	bitmapMarkPopulatedScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return redis.error_reply('ERR no such key')
+end
+redis.call('HSET', KEYS[1], 'populated', ARGV[1])
+return 1
+`)
+
+	// 没有任何元素的位数组不存在，此时同样视为未装载，只是少了拒绝的机会
+	bitmapPopulatedScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[2], 'populated') == 1 and redis.call('EXISTS', KEYS[1]) == 1 then
+	return 1
+end
+return 0
+`)
+
+	// 位数组可能为空（从未 SETBIT），此时目标位数组也需要清除
+	bitmapRenameScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return redis.error_reply('ERR no such key')
+end
+redis.call('RENAME', KEYS[2], KEYS[4])
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('RENAME', KEYS[1], KEYS[3])
+else
+	redis.call('DEL', KEYS[3])
+end
+return 1
+`)
+)
+
+// bitmapBloomBackend 基于 Redis 位图（SETBIT/GETBIT）的纯 Go 布隆过滤器后端
+type bitmapBloomBackend struct {
+	client    *redis.Client
+	capacity  int
+	errorRate float64
+}
+
+func newBitmapBloomBackend(client *redis.Client, capacity int, errorRate float64) *bitmapBloomBackend {
+	return &bitmapBloomBackend{
+		client:    client,
+		capacity:  capacity,
+		errorRate: errorRate,
+	}
+}
+
+func (b *bitmapBloomBackend) name() string {
+	return BloomBackendBitmap
+}
+
+func (b *bitmapBloomBackend) reserve(ctx context.Context, key string, capacity int, errorRate float64) error {
+	bits, hashes := bloomParams(capacity, errorRate)
+	return bitmapReserveScript.Run(ctx, b.client, []string{bitmapMetaKey(key)},
+		capacity, errorRate, bits, hashes).Err()
+}
+
+func (b *bitmapBloomBackend) madd(ctx context.Context, key string, items []string) ([]bool, error) {
+	bits, hashes := bloomParams(b.capacity, b.errorRate)
+	args := make([]interface{}, 0, 4+len(items)*2)
+	args = append(args, b.capacity, b.errorRate, bits, hashes)
+	args = appendBloomHashArgs(args, items)
+
+	results, err := bitmapAddScript.Run(ctx, b.client, []string{key, bitmapMetaKey(key)}, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return int64sToBools(results), nil
+}
+
+func (b *bitmapBloomBackend) mexists(ctx context.Context, key string, items []string) ([]bool, error) {
+	args := appendBloomHashArgs(make([]interface{}, 0, len(items)*2), items)
+
+	results, err := bitmapExistsScript.Run(ctx, b.client, []string{key, bitmapMetaKey(key)}, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return int64sToBools(results), nil
+}
+
+func (b *bitmapBloomBackend) info(ctx context.Context, key string) (map[string]interface{}, error) {
+	meta, err := b.client.HGetAll(ctx, bitmapMetaKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) == 0 {
+		return nil, ErrBloomFilterNotFound
+	}
+
+	capacity, _ := strconv.ParseInt(meta["capacity"], 10, 64)
+	bits, _ := strconv.ParseInt(meta["bits"], 10, 64)
+	items, _ := strconv.ParseInt(meta["items"], 10, 64)
+
+	return bloomInfo(capacity, (bits+7)/8, items), nil
+}
+
+func (b *bitmapBloomBackend) remove(ctx context.Context, key string) error {
+	return b.client.Del(ctx, key, bitmapMetaKey(key)).Err()
+}
+
+func (b *bitmapBloomBackend) rename(ctx context.Context, from, to string) error {
+	keys := []string{from, bitmapMetaKey(from), to, bitmapMetaKey(to)}
+	return bitmapRenameScript.Run(ctx, b.client, keys).Err()
+}
+
+func (b *bitmapBloomBackend) markPopulated(ctx context.Context, key string) error {
+	return bitmapMarkPopulatedScript.Run(ctx, b.client, []string{bitmapMetaKey(key)}, time.Now().Unix()).Err()
+}
+
+func (b *bitmapBloomBackend) populated(ctx context.Context, key string) (bool, error) {
+	n, err := bitmapPopulatedScript.Run(ctx, b.client, []string{key, bitmapMetaKey(key)}).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// bitmapMetaKey 位图后端参数哈希的键名
+func bitmapMetaKey(key string) string {
+	return fmt.Sprintf("%s:meta", key)
+}
+
+// appendBloomHashArgs 将每个元素的 h1、h2 追加为脚本参数
+func appendBloomHashArgs(args []interface{}, items []string) []interface{} {
+	for _, item := range items {
+		h1, h2 := bloomHashes(item)
+		args = append(args, h1, h2)
+	}
+	return args
+}
+
+func int64sToBools(values []int64) []bool {
+	results := make([]bool, len(values))
+	for i, v := range values {
+		results[i] = v == 1
+	}
+	return results
+}
+
+// bloomInfo 构造与 BF.INFO 字段一致的信息
+func bloomInfo(capacity, size, items int64) map[string]interface{} {
+	return map[string]interface{}{
+		BloomInfoCapacity:      capacity,
+		BloomInfoSize:          size,
+		BloomInfoFilters:       int64(1),
+		BloomInfoItemsInserted: items,
+		BloomInfoExpansionRate: int64(0),
+	}
+}
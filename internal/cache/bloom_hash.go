@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomParams 根据容量和错误率计算位数组大小 m 与哈希函数个数 k
+func bloomParams(capacity int, errorRate float64) (bits uint64, hashes uint64) {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	if errorRate <= 0 || errorRate >= 1 {
+		errorRate = 0.001
+	}
+
+	n := float64(capacity)
+	m := math.Ceil(-n * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / n * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+
+	return uint64(m), uint64(k)
+}
+
+// bloomHashes 计算元素的两个 32 位基础哈希，第 i 个位置为 (h1 + i*h2) mod m。
+// 使用 32 位是为了让 Lua 脚本中的双精度运算保持精确。
+func bloomHashes(item string) (h1, h2 uint64) {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(item))
+	sum := hasher.Sum64()
+
+	h1 = sum & 0xffffffff
+	h2 = (sum >> 32) | 1
+	return h1, h2
+}
+
+// bloomOffsets 计算元素在位数组中的 k 个位置
+func bloomOffsets(item string, bits, hashes uint64) []uint64 {
+	h1, h2 := bloomHashes(item)
+	offsets := make([]uint64, hashes)
+	for i := uint64(0); i < hashes; i++ {
+		offsets[i] = (h1 + i*h2) % bits
+	}
+	return offsets
+}
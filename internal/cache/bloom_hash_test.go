@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBloomParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		capacity   int
+		errorRate  float64
+		wantBits   uint64
+		wantHashes uint64
+	}{
+		{name: "1k at 1%", capacity: 1000, errorRate: 0.01, wantBits: 9586, wantHashes: 7},
+		{name: "1m at 0.1%", capacity: 1000000, errorRate: 0.001, wantBits: 14377588, wantHashes: 10},
+		{name: "non-positive capacity treated as one", capacity: 0, errorRate: 0.01, wantBits: 10, wantHashes: 7},
+		{name: "invalid error rate falls back to 0.1%", capacity: 1000, errorRate: 1.5, wantBits: 14378, wantHashes: 10},
+		{name: "zero error rate falls back to 0.1%", capacity: 1000, errorRate: 0, wantBits: 14378, wantHashes: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bits, hashes := bloomParams(tt.capacity, tt.errorRate)
+			if bits != tt.wantBits || hashes != tt.wantHashes {
+				t.Errorf("bloomParams(%d, %v) = (%d, %d), want (%d, %d)",
+					tt.capacity, tt.errorRate, bits, hashes, tt.wantBits, tt.wantHashes)
+			}
+		})
+	}
+}
+
+func TestBloomHashes(t *testing.T) {
+	// Lua 脚本用双精度计算 h1 + i*h2，必须保持在 2^53 以内才精确
+	const maxExact = uint64(1) << 53
+	const maxHashes = 64
+
+	tests := []string{"", "a", "abc123", "ZZZZZZ", "短链接", "a-very-long-item-that-is-longer-than-a-short-code"}
+
+	for _, item := range tests {
+		t.Run(fmt.Sprintf("%q", item), func(t *testing.T) {
+			h1, h2 := bloomHashes(item)
+			if h1 > 0xffffffff || h2 > 0xffffffff {
+				t.Fatalf("bloomHashes(%q) = (%d, %d), want 32-bit values", item, h1, h2)
+			}
+			if h2%2 == 0 {
+				t.Errorf("bloomHashes(%q) h2 = %d, want odd so offsets do not collapse", item, h2)
+			}
+			if h1+(maxHashes-1)*h2 >= maxExact {
+				t.Errorf("bloomHashes(%q) offsets exceed 2^53", item)
+			}
+
+			if g1, g2 := bloomHashes(item); g1 != h1 || g2 != h2 {
+				t.Errorf("bloomHashes(%q) is not deterministic", item)
+			}
+		})
+	}
+}
+
+func TestBloomOffsets(t *testing.T) {
+	tests := []struct {
+		item   string
+		bits   uint64
+		hashes uint64
+	}{
+		{item: "abc123", bits: 9586, hashes: 7},
+		{item: "abc124", bits: 9586, hashes: 7},
+		{item: "x", bits: 1, hashes: 3},
+		{item: "x", bits: 14377588, hashes: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d/%d", tt.item, tt.bits, tt.hashes), func(t *testing.T) {
+			offsets := bloomOffsets(tt.item, tt.bits, tt.hashes)
+			if uint64(len(offsets)) != tt.hashes {
+				t.Fatalf("got %d offsets, want %d", len(offsets), tt.hashes)
+			}
+
+			// 与位图后端 Lua 脚本使用相同的公式
+			h1, h2 := bloomHashes(tt.item)
+			for i, offset := range offsets {
+				if offset >= tt.bits {
+					t.Errorf("offset[%d] = %d, want < %d", i, offset, tt.bits)
+				}
+				if want := (h1 + uint64(i)*h2) % tt.bits; offset != want {
+					t.Errorf("offset[%d] = %d, want %d", i, offset, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendBloomHashArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix []interface{}
+		items  []string
+	}{
+		{name: "no items", items: nil},
+		{name: "after script params", prefix: []interface{}{1000, 0.01, uint64(9586), uint64(7)}, items: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := appendBloomHashArgs(append([]interface{}{}, tt.prefix...), tt.items)
+			if len(args) != len(tt.prefix)+2*len(tt.items) {
+				t.Fatalf("len(args) = %d, want %d", len(args), len(tt.prefix)+2*len(tt.items))
+			}
+			for i, item := range tt.items {
+				h1, h2 := bloomHashes(item)
+				got1, got2 := args[len(tt.prefix)+2*i], args[len(tt.prefix)+2*i+1]
+				if got1 != h1 || got2 != h2 {
+					t.Errorf("args for %q = (%v, %v), want (%d, %d)", item, got1, got2, h1, h2)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateFalsePositiveRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    int64
+		min, max float64
+	}{
+		{name: "empty", items: 0, min: 0, max: 0},
+		{name: "at capacity close to target", items: 1000, min: 0.005, max: 0.015},
+		{name: "over capacity degrades", items: 3000, min: 0.1, max: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateFalsePositiveRate(1000, 0.01, tt.items)
+			if got < tt.min || got > tt.max {
+				t.Errorf("EstimateFalsePositiveRate(1000, 0.01, %d) = %v, want in [%v, %v]", tt.items, got, tt.min, tt.max)
+			}
+		})
+	}
+}
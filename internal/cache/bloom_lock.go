@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrBloomFilterLocked 过滤器正在被其他任务重建或轮换
+var ErrBloomFilterLocked = errors.New("bloom filter maintenance already in progress")
+
+const bloomLockTTL = time.Minute
+
+var (
+	bloomUnlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+	bloomRefreshLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// Lock 获取过滤器维护锁，保证重建与轮换在进程内和多实例间互斥。
+// 持有期间会自动续期，调用返回的函数释放锁。
+func (bf *BloomFilter) Lock(ctx context.Context) (func(), error) {
+	if !bf.maintenance.TryLock() {
+		return nil, ErrBloomFilterLocked
+	}
+
+	// 进程内后端只需要本地锁
+	if bf.redisClient == nil || bf.Backend() == BloomBackendMemory {
+		return bf.maintenance.Unlock, nil
+	}
+
+	client := bf.redisClient.GetClient()
+	lockKey := fmt.Sprintf("%s:lock", bf.config.Key)
+	token, err := randomToken()
+	if err != nil {
+		bf.maintenance.Unlock()
+		return nil, err
+	}
+
+	ok, err := client.SetNX(ctx, lockKey, token, bloomLockTTL).Result()
+	if err != nil {
+		bf.maintenance.Unlock()
+		return nil, fmt.Errorf("failed to acquire bloom filter lock: %w", err)
+	}
+	if !ok {
+		bf.maintenance.Unlock()
+		return nil, ErrBloomFilterLocked
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(bloomLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				bloomRefreshLockScript.Run(context.Background(), client, []string{lockKey}, token, bloomLockTTL.Milliseconds())
+			}
+		}
+	}()
+
+	unlock := func() {
+		close(stop)
+		<-done
+		bloomUnlockScript.Run(context.Background(), client, []string{lockKey}, token)
+		bf.maintenance.Unlock()
+	}
+
+	return unlock, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryBloomBackend 进程内布隆过滤器后端，无需 Redis
+type memoryBloomBackend struct {
+	capacity  int
+	errorRate float64
+
+	mu      sync.RWMutex
+	filters map[string]*memoryBloom
+}
+
+type memoryBloom struct {
+	capacity int
+	bits     uint64
+	hashes   uint64
+	items    int64
+	words    []uint64
+	// populated 已完整装载全部短码，随过滤器一起替换
+	populated bool
+}
+
+func newMemoryBloomBackend(capacity int, errorRate float64) *memoryBloomBackend {
+	return &memoryBloomBackend{
+		capacity:  capacity,
+		errorRate: errorRate,
+		filters:   make(map[string]*memoryBloom),
+	}
+}
+
+func newMemoryBloom(capacity int, errorRate float64) *memoryBloom {
+	bits, hashes := bloomParams(capacity, errorRate)
+	return &memoryBloom{
+		capacity: capacity,
+		bits:     bits,
+		hashes:   hashes,
+		words:    make([]uint64, (bits+63)/64),
+	}
+}
+
+func (b *memoryBloomBackend) name() string {
+	return BloomBackendMemory
+}
+
+func (b *memoryBloomBackend) reserve(ctx context.Context, key string, capacity int, errorRate float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.filters[key]; !ok {
+		b.filters[key] = newMemoryBloom(capacity, errorRate)
+	}
+	return nil
+}
+
+func (b *memoryBloomBackend) madd(ctx context.Context, key string, items []string) ([]bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	filter, ok := b.filters[key]
+	if !ok {
+		filter = newMemoryBloom(b.capacity, b.errorRate)
+		b.filters[key] = filter
+	}
+
+	results := make([]bool, len(items))
+	for i, item := range items {
+		for _, offset := range bloomOffsets(item, filter.bits, filter.hashes) {
+			word, mask := offset/64, uint64(1)<<(offset%64)
+			if filter.words[word]&mask == 0 {
+				filter.words[word] |= mask
+				results[i] = true
+			}
+		}
+		if results[i] {
+			filter.items++
+		}
+	}
+
+	return results, nil
+}
+
+func (b *memoryBloomBackend) mexists(ctx context.Context, key string, items []string) ([]bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	results := make([]bool, len(items))
+	filter, ok := b.filters[key]
+	if !ok {
+		return results, nil
+	}
+
+	for i, item := range items {
+		results[i] = true
+		for _, offset := range bloomOffsets(item, filter.bits, filter.hashes) {
+			if filter.words[offset/64]&(uint64(1)<<(offset%64)) == 0 {
+				results[i] = false
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (b *memoryBloomBackend) info(ctx context.Context, key string) (map[string]interface{}, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	filter, ok := b.filters[key]
+	if !ok {
+		return nil, ErrBloomFilterNotFound
+	}
+
+	return bloomInfo(int64(filter.capacity), int64(len(filter.words)*8), filter.items), nil
+}
+
+func (b *memoryBloomBackend) remove(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.filters, key)
+	return nil
+}
+
+func (b *memoryBloomBackend) rename(ctx context.Context, from, to string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	filter, ok := b.filters[from]
+	if !ok {
+		return ErrBloomFilterNotFound
+	}
+	b.filters[to] = filter
+	delete(b.filters, from)
+	return nil
+}
+
+func (b *memoryBloomBackend) markPopulated(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	filter, ok := b.filters[key]
+	if !ok {
+		return ErrBloomFilterNotFound
+	}
+	filter.populated = true
+	return nil
+}
+
+func (b *memoryBloomBackend) populated(ctx context.Context, key string) (bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	filter, ok := b.filters[key]
+	return ok && filter.populated, nil
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisBloomBackend 基于 RedisBloom 模块（BF.* 命令）的后端。
+// 完整装载标记保存在 <key>:populated 中，RedisBloom 过滤器本身无法附带字段
+type redisBloomBackend struct {
+	client    *redis.Client
+	capacity  int
+	errorRate float64
+}
+
+func newRedisBloomBackend(client *redis.Client, capacity int, errorRate float64) *redisBloomBackend {
+	return &redisBloomBackend{
+		client:    client,
+		capacity:  capacity,
+		errorRate: errorRate,
+	}
+}
+
+func (b *redisBloomBackend) name() string {
+	return BloomBackendRedisBloom
+}
+
+func (b *redisBloomBackend) reserve(ctx context.Context, key string, capacity int, errorRate float64) error {
+	exists, err := b.client.Exists(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check bloom filter existence: %w", err)
+	}
+	if exists == 1 {
+		return nil
+	}
+
+	// BF.RESERVE key error_rate capacity
+	return b.client.Do(ctx, "BF.RESERVE", key, errorRate, capacity).Err()
+}
+
+// madd 不自动创建过滤器。过滤器被删除或淘汰后，先清除装载标记再按配置重新创建，
+// 否则新建的空过滤器会和残留的标记一起被当作完整装载
+func (b *redisBloomBackend) madd(ctx context.Context, key string, items []string) ([]bool, error) {
+	results, err := b.multi(ctx, []interface{}{"BF.INSERT", key, "NOCREATE", "ITEMS"}, items)
+	if err == nil || !isNotFoundError(err) {
+		return results, err
+	}
+
+	if err := b.client.Del(ctx, bloomPopulatedKey(key)).Err(); err != nil {
+		return nil, fmt.Errorf("failed to clear bloom filter populated marker: %w", err)
+	}
+	if err := b.reserve(ctx, key, b.capacity, b.errorRate); err != nil {
+		return nil, fmt.Errorf("failed to recreate bloom filter: %w", err)
+	}
+	return b.multi(ctx, []interface{}{"BF.MADD", key}, items)
+}
+
+func (b *redisBloomBackend) mexists(ctx context.Context, key string, items []string) ([]bool, error) {
+	return b.multi(ctx, []interface{}{"BF.MEXISTS", key}, items)
+}
+
+func (b *redisBloomBackend) info(ctx context.Context, key string) (map[string]interface{}, error) {
+	cmd := b.client.Do(ctx, "BF.INFO", key)
+	if err := cmd.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := cmd.Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	info := make(map[string]interface{})
+	for i := 0; i < len(result); i += 2 {
+		if i+1 < len(result) {
+			key := fmt.Sprintf("%v", result[i])
+			info[key] = result[i+1]
+		}
+	}
+
+	return info, nil
+}
+
+func (b *redisBloomBackend) remove(ctx context.Context, key string) error {
+	return b.client.Del(ctx, key, bloomPopulatedKey(key)).Err()
+}
+
+func (b *redisBloomBackend) rename(ctx context.Context, from, to string) error {
+	return b.client.Rename(ctx, from, to).Err()
+}
+
+func (b *redisBloomBackend) markPopulated(ctx context.Context, key string) error {
+	return b.client.Set(ctx, bloomPopulatedKey(key), time.Now().Unix(), 0).Err()
+}
+
+// populated 标记与过滤器同时存在时才视为完整装载
+func (b *redisBloomBackend) populated(ctx context.Context, key string) (bool, error) {
+	n, err := b.client.Exists(ctx, bloomPopulatedKey(key), key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n == 2, nil
+}
+
+// multi 执行 BF.INSERT / BF.MADD / BF.MEXISTS 并解析结果，command 为元素之前的命令和参数
+func (b *redisBloomBackend) multi(ctx context.Context, command []interface{}, items []string) ([]bool, error) {
+	args := make([]interface{}, 0, len(command)+len(items))
+	args = append(args, command...)
+	for _, item := range items {
+		args = append(args, item)
+	}
+
+	cmd := b.client.Do(ctx, args...)
+	if err := cmd.Err(); err != nil {
+		return nil, err
+	}
+
+	results, err := cmd.Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	boolResults := make([]bool, len(results))
+	for i, result := range results {
+		if val, ok := result.(int64); ok {
+			boolResults[i] = val == 1
+		}
+	}
+
+	return boolResults, nil
+}
+
+// bloomPopulatedKey 完整装载标记的键名
+func bloomPopulatedKey(key string) string {
+	return fmt.Sprintf("%s:populated", key)
+}
+
+// isNotFoundError 判断是否为 NOCREATE 时过滤器不存在的错误
+func isNotFoundError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "not found")
+}
@@ -2,94 +2,140 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"short-url/internal/config"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// 布隆过滤器后端类型
+const (
+	BloomBackendAuto       = "auto"
+	BloomBackendRedisBloom = "redisbloom"
+	BloomBackendBitmap     = "bitmap"
+	BloomBackendMemory     = "memory"
+)
+
+// BF.INFO 返回的字段名，所有后端保持一致
+const (
+	BloomInfoCapacity      = "Capacity"
+	BloomInfoSize          = "Size"
+	BloomInfoFilters       = "Number of filters"
+	BloomInfoItemsInserted = "Number of items inserted"
+	BloomInfoExpansionRate = "Expansion rate"
+)
+
+// ErrBloomFilterNotFound 布隆过滤器不存在
+var ErrBloomFilterNotFound = errors.New("bloom filter not found")
+
+// bloomBackend 布隆过滤器存储后端，所有实现对外行为保持一致
+type bloomBackend interface {
+	name() string
+	// reserve 在 key 不存在时按容量和错误率创建过滤器
+	reserve(ctx context.Context, key string, capacity int, errorRate float64) error
+	madd(ctx context.Context, key string, items []string) ([]bool, error)
+	mexists(ctx context.Context, key string, items []string) ([]bool, error)
+	info(ctx context.Context, key string) (map[string]interface{}, error)
+	// remove 删除过滤器
+	remove(ctx context.Context, key string) error
+	// rename 原子地用 from 替换 to
+	rename(ctx context.Context, from, to string) error
+	// markPopulated 标记过滤器已完整装载全部短码
+	markPopulated(ctx context.Context, key string) error
+	// populated 过滤器存在且带有完整装载标记时返回 true
+	populated(ctx context.Context, key string) (bool, error)
+}
+
 type BloomFilter struct {
 	redisClient *RedisClient
 	config      *config.BloomFilterConfig
+
+	mu      sync.RWMutex
+	backend bloomBackend
+
+	// maintenance 保证同一进程内只有一个重建或轮换任务
+	maintenance sync.Mutex
+	// mirror 重建期间同时写入的临时过滤器，避免重建过程中新增的元素在替换后丢失
+	mirror atomic.Pointer[BloomFilter]
 }
 
 func NewBloomFilter(redisClient *RedisClient, config *config.BloomFilterConfig) *BloomFilter {
-	return &BloomFilter{
+	bf := &BloomFilter{
 		redisClient: redisClient,
 		config:      config,
 	}
+
+	// 自动模式下先按可用组件给出默认后端，Initialize 时再探测确认
+	switch {
+	case config.Backend == BloomBackendMemory || redisClient == nil:
+		bf.backend = newMemoryBloomBackend(config.Capacity, config.ErrorRate)
+	case config.Backend == BloomBackendBitmap:
+		bf.backend = newBitmapBloomBackend(redisClient.GetClient(), config.Capacity, config.ErrorRate)
+	default:
+		bf.backend = newRedisBloomBackend(redisClient.GetClient(), config.Capacity, config.ErrorRate)
+	}
+
+	return bf
 }
 
 // Initialize 初始化布隆过滤器
 func (bf *BloomFilter) Initialize(ctx context.Context) error {
-	// 检查布隆过滤器是否已存在
-	exists, err := bf.redisClient.Exists(ctx, bf.config.Key)
-	if err != nil {
-		return fmt.Errorf("failed to check bloom filter existence: %w", err)
+	if bf.config.Backend == BloomBackendAuto || bf.config.Backend == "" {
+		backend, err := bf.detectBackend(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to detect bloom filter backend: %w", err)
+		}
+		bf.setBackend(backend)
 	}
 
-	if !exists {
-		// 创建布隆过滤器
-		// BF.RESERVE key error_rate capacity
-		cmd := bf.redisClient.GetClient().Do(ctx, "BF.RESERVE", bf.config.Key, bf.config.ErrorRate, bf.config.Capacity)
-		if err := cmd.Err(); err != nil {
-			return fmt.Errorf("failed to create bloom filter: %w", err)
-		}
+	if err := bf.getBackend().reserve(ctx, bf.config.Key, bf.config.Capacity, bf.config.ErrorRate); err != nil {
+		return fmt.Errorf("failed to create bloom filter: %w", err)
 	}
 
 	return nil
 }
 
+// Backend 返回当前使用的后端名称
+func (bf *BloomFilter) Backend() string {
+	return bf.getBackend().name()
+}
+
 // Add 向布隆过滤器添加元素
 func (bf *BloomFilter) Add(ctx context.Context, item string) error {
-	cmd := bf.redisClient.GetClient().Do(ctx, "BF.ADD", bf.config.Key, item)
-	return cmd.Err()
+	_, err := bf.MAdd(ctx, []string{item})
+	return err
 }
 
 // Exists 检查元素是否可能存在于布隆过滤器中
 func (bf *BloomFilter) Exists(ctx context.Context, item string) (bool, error) {
-	cmd := bf.redisClient.GetClient().Do(ctx, "BF.EXISTS", bf.config.Key, item)
-	if err := cmd.Err(); err != nil {
-		return false, err
-	}
-
-	result, err := cmd.Int()
+	results, err := bf.getBackend().mexists(ctx, bf.config.Key, []string{item})
 	if err != nil {
 		return false, err
 	}
-
-	return result == 1, nil
+	return results[0], nil
 }
 
-// MAdd 批量添加元素
+// MAdd 批量添加元素，返回每个元素是否为新添加
 func (bf *BloomFilter) MAdd(ctx context.Context, items []string) ([]bool, error) {
 	if len(items) == 0 {
 		return []bool{}, nil
 	}
 
-	args := make([]interface{}, len(items)+2)
-	args[0] = "BF.MADD"
-	args[1] = bf.config.Key
-	for i, item := range items {
-		args[i+2] = item
-	}
-
-	cmd := bf.redisClient.GetClient().Do(ctx, args...)
-	if err := cmd.Err(); err != nil {
-		return nil, err
-	}
-
-	results, err := cmd.Slice()
+	results, err := bf.getBackend().madd(ctx, bf.config.Key, items)
 	if err != nil {
 		return nil, err
 	}
 
-	boolResults := make([]bool, len(results))
-	for i, result := range results {
-		if val, ok := result.(int64); ok {
-			boolResults[i] = val == 1
+	if mirror := bf.mirror.Load(); mirror != nil {
+		if _, err := mirror.MAdd(ctx, items); err != nil {
+			return results, fmt.Errorf("failed to add to staging bloom filter: %w", err)
 		}
 	}
-
-	return boolResults, nil
+	return results, nil
 }
 
 // MExists 批量检查元素是否存在
@@ -97,53 +143,140 @@ func (bf *BloomFilter) MExists(ctx context.Context, items []string) ([]bool, err
 	if len(items) == 0 {
 		return []bool{}, nil
 	}
+	return bf.getBackend().mexists(ctx, bf.config.Key, items)
+}
+
+// Info 获取布隆过滤器信息，字段与 BF.INFO 保持一致
+func (bf *BloomFilter) Info(ctx context.Context) (map[string]interface{}, error) {
+	return bf.getBackend().info(ctx, bf.config.Key)
+}
+
+// Key 返回过滤器在后端中的键名
+func (bf *BloomFilter) Key() string {
+	return bf.config.Key
+}
 
-	args := make([]interface{}, len(items)+2)
-	args[0] = "BF.MEXISTS"
-	args[1] = bf.config.Key
-	for i, item := range items {
-		args[i+2] = item
+// Staging 基于同一后端创建一个临时过滤器，用于重建后整体替换当前过滤器。
+// 容量取 capacity 与配置容量中的较大值。
+func (bf *BloomFilter) Staging(capacity int) *BloomFilter {
+	stagingConfig := *bf.config
+	stagingConfig.Key = fmt.Sprintf("%s:rebuild", bf.config.Key)
+	if capacity > stagingConfig.Capacity {
+		stagingConfig.Capacity = capacity
 	}
 
-	cmd := bf.redisClient.GetClient().Do(ctx, args...)
-	if err := cmd.Err(); err != nil {
-		return nil, err
+	return &BloomFilter{
+		redisClient: bf.redisClient,
+		config:      &stagingConfig,
+		backend:     bf.getBackend(),
 	}
+}
 
-	results, err := cmd.Slice()
-	if err != nil {
-		return nil, err
+// Reset 清空过滤器并按当前配置重新创建
+func (bf *BloomFilter) Reset(ctx context.Context) error {
+	backend := bf.getBackend()
+	if err := backend.remove(ctx, bf.config.Key); err != nil {
+		return fmt.Errorf("failed to remove bloom filter: %w", err)
 	}
+	if err := backend.reserve(ctx, bf.config.Key, bf.config.Capacity, bf.config.ErrorRate); err != nil {
+		return fmt.Errorf("failed to create bloom filter: %w", err)
+	}
+	return nil
+}
 
-	boolResults := make([]bool, len(results))
-	for i, result := range results {
-		if val, ok := result.(int64); ok {
-			boolResults[i] = val == 1
-		}
+// Mirror 开始把新增元素同时写入 staging，直到调用返回的函数为止。
+// 只覆盖本进程内的写入，其他实例的写入由重建后的补扫处理
+func (bf *BloomFilter) Mirror(staging *BloomFilter) func() {
+	bf.mirror.Store(staging)
+	return func() {
+		bf.mirror.CompareAndSwap(staging, nil)
 	}
+}
+
+// Promote 用 staging 过滤器原子替换当前过滤器
+func (bf *BloomFilter) Promote(ctx context.Context, staging *BloomFilter) error {
+	if staging.getBackend() != bf.getBackend() {
+		return fmt.Errorf("staging bloom filter uses a different backend")
+	}
+	if err := bf.getBackend().rename(ctx, staging.config.Key, bf.config.Key); err != nil {
+		return fmt.Errorf("failed to promote bloom filter: %w", err)
+	}
+	return nil
+}
 
-	return boolResults, nil
+// MarkPopulated 标记过滤器已完整装载全部短码，此后其"不存在"的判断才可信
+func (bf *BloomFilter) MarkPopulated(ctx context.Context) error {
+	return bf.getBackend().markPopulated(ctx, bf.config.Key)
 }
 
-// Info 获取布隆过滤器信息
-func (bf *BloomFilter) Info(ctx context.Context) (map[string]interface{}, error) {
-	cmd := bf.redisClient.GetClient().Do(ctx, "BF.INFO", bf.config.Key)
-	if err := cmd.Err(); err != nil {
-		return nil, err
+// Populated 判断过滤器是否已完整装载。进程重启后的内存过滤器、
+// 被清空或淘汰后重新创建的 Redis 过滤器都没有该标记
+func (bf *BloomFilter) Populated(ctx context.Context) (bool, error) {
+	return bf.getBackend().populated(ctx, bf.config.Key)
+}
+
+// detectBackend 探测 Redis 是否加载了 RedisBloom 模块
+func (bf *BloomFilter) detectBackend(ctx context.Context) (bloomBackend, error) {
+	if bf.redisClient == nil {
+		return newMemoryBloomBackend(bf.config.Capacity, bf.config.ErrorRate), nil
 	}
 
-	result, err := cmd.Slice()
+	client := bf.redisClient.GetClient()
+
+	// 已存在位图版本的过滤器时沿用，避免与 RedisBloom 类型冲突
+	exists, err := bf.redisClient.Exists(ctx, bitmapMetaKey(bf.config.Key))
 	if err != nil {
 		return nil, err
 	}
+	if exists {
+		return newBitmapBloomBackend(client, bf.config.Capacity, bf.config.ErrorRate), nil
+	}
 
-	info := make(map[string]interface{})
-	for i := 0; i < len(result); i += 2 {
-		if i+1 < len(result) {
-			key := fmt.Sprintf("%v", result[i])
-			info[key] = result[i+1]
-		}
+	err = client.Do(ctx, "BF.EXISTS", bf.config.Key, "").Err()
+	if err != nil && isUnknownCommandError(err) {
+		return newBitmapBloomBackend(client, bf.config.Capacity, bf.config.ErrorRate), nil
 	}
 
-	return info, nil
+	return newRedisBloomBackend(client, bf.config.Capacity, bf.config.ErrorRate), nil
+}
+
+func (bf *BloomFilter) getBackend() bloomBackend {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+	return bf.backend
+}
+
+func (bf *BloomFilter) setBackend(backend bloomBackend) {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	bf.backend = backend
+}
+
+// isUnknownCommandError 判断是否为命令不存在错误（未加载模块）
+func isUnknownCommandError(err error) bool {
+	return strings.HasPrefix(strings.ToLower(err.Error()), "err unknown command")
+}
+
+// EstimateFalsePositiveRate 按容量和目标错误率推算的过滤器参数，估算插入 items 个元素后的实际误判率
+func EstimateFalsePositiveRate(capacity int, errorRate float64, items int64) float64 {
+	bits, hashes := bloomParams(capacity, errorRate)
+	k := float64(hashes)
+	return math.Pow(1-math.Exp(-k*float64(items)/float64(bits)), k)
+}
+
+// InfoInt 从 Info 结果中读取整数字段
+func InfoInt(info map[string]interface{}, field string) int64 {
+	switch v := info[field].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	default:
+		return 0
+	}
 }
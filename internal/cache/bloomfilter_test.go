@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"short-url/internal/config"
+	"slices"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestNewBloomFilterBackend(t *testing.T) {
+	// 创建客户端不会连接 Redis，只检查按配置选择的后端
+	redisClient := NewRedisClient(&config.RedisConfig{Host: "127.0.0.1", Port: 1}, &config.CacheConfig{})
+	defer redisClient.Close()
+
+	tests := []struct {
+		name    string
+		backend string
+		redis   *RedisClient
+		want    string
+	}{
+		{name: "memory configured", backend: BloomBackendMemory, redis: redisClient, want: BloomBackendMemory},
+		{name: "no redis falls back to memory", backend: BloomBackendRedisBloom, redis: nil, want: BloomBackendMemory},
+		{name: "auto without redis", backend: BloomBackendAuto, redis: nil, want: BloomBackendMemory},
+		{name: "bitmap configured", backend: BloomBackendBitmap, redis: redisClient, want: BloomBackendBitmap},
+		{name: "redisbloom configured", backend: BloomBackendRedisBloom, redis: redisClient, want: BloomBackendRedisBloom},
+		{name: "auto defaults to redisbloom until detected", backend: BloomBackendAuto, redis: redisClient, want: BloomBackendRedisBloom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := NewBloomFilter(tt.redis, &config.BloomFilterConfig{Backend: tt.backend, Key: "test", Capacity: 100, ErrorRate: 0.01})
+			if got := bf.Backend(); got != tt.want {
+				t.Errorf("Backend() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectBackendWithoutRedis(t *testing.T) {
+	bf := NewBloomFilter(nil, &config.BloomFilterConfig{Backend: BloomBackendAuto, Key: "test", Capacity: 100, ErrorRate: 0.01})
+
+	backend, err := bf.detectBackend(context.Background())
+	if err != nil {
+		t.Fatalf("detectBackend() error = %v", err)
+	}
+	if backend.name() != BloomBackendMemory {
+		t.Errorf("detectBackend() = %q, want %q", backend.name(), BloomBackendMemory)
+	}
+}
+
+func TestIsUnknownCommandError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: errors.New("ERR unknown command 'BF.EXISTS', with args beginning with: 'key' ''"), want: true},
+		{err: errors.New("ERR unknown command `BF.EXISTS`"), want: true},
+		{err: errors.New("err Unknown Command 'bf.exists'"), want: true},
+		{err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), want: false},
+		{err: errors.New("dial tcp 127.0.0.1:6379: connect: connection refused"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := isUnknownCommandError(tt.err); got != tt.want {
+				t.Errorf("isUnknownCommandError(%q) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryBloomFilter(t *testing.T) {
+	ctx := context.Background()
+	bf := NewBloomFilter(nil, &config.BloomFilterConfig{Backend: BloomBackendAuto, Key: "test", Capacity: 1000, ErrorRate: 0.001})
+	if err := bf.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	added, err := bf.MAdd(ctx, []string{"abc123", "def456", "abc123"})
+	if err != nil {
+		t.Fatalf("MAdd() error = %v", err)
+	}
+	if want := []bool{true, true, false}; !slices.Equal(added, want) {
+		t.Errorf("MAdd() = %v, want %v", added, want)
+	}
+
+	tests := []struct {
+		item string
+		want bool
+	}{
+		{item: "abc123", want: true},
+		{item: "def456", want: true},
+		{item: "zzz999", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.item, func(t *testing.T) {
+			exists, err := bf.Exists(ctx, tt.item)
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if exists != tt.want {
+				t.Errorf("Exists(%q) = %v, want %v", tt.item, exists, tt.want)
+			}
+		})
+	}
+
+	info, err := bf.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if got := InfoInt(info, BloomInfoItemsInserted); got != 2 {
+		t.Errorf("items inserted = %d, want 2", got)
+	}
+	if got := InfoInt(info, BloomInfoCapacity); got != 1000 {
+		t.Errorf("capacity = %d, want 1000", got)
+	}
+}
+
+func TestInfoInt(t *testing.T) {
+	info := map[string]interface{}{
+		"int64":   int64(7),
+		"int":     8,
+		"float":   9.0,
+		"string":  "10",
+		"invalid": "x",
+		"other":   []byte("11"),
+	}
+
+	tests := []struct {
+		field string
+		want  int64
+	}{
+		{field: "int64", want: 7},
+		{field: "int", want: 8},
+		{field: "float", want: 9},
+		{field: "string", want: 10},
+		{field: "invalid", want: 0},
+		{field: "other", want: 0},
+		{field: "missing", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := InfoInt(info, tt.field); got != tt.want {
+				t.Errorf("InfoInt(%q) = %d, want %d", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitmapBloomFilterPopulated(t *testing.T) {
+	tests := []struct {
+		name string
+		// lose 模拟过滤器在标记完整装载之后发生的变化
+		lose func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter)
+		want bool
+	}{
+		{
+			name: "marked",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {},
+			want: true,
+		},
+		{
+			name: "add after marking",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				mustAdd(t, bf, "ghi789")
+			},
+			want: true,
+		},
+		{
+			name: "bit array deleted",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				server.Del(bf.Key())
+			},
+			want: false,
+		},
+		{
+			name: "bit array deleted then add",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				server.Del(bf.Key())
+				mustAdd(t, bf, "ghi789")
+			},
+			want: false,
+		},
+		{
+			name: "meta deleted then add",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				server.Del(bitmapMetaKey(bf.Key()))
+				mustAdd(t, bf, "ghi789")
+			},
+			want: false,
+		},
+		{
+			name: "flushed then add",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				server.FlushAll()
+				mustAdd(t, bf, "ghi789")
+			},
+			want: false,
+		},
+		{
+			name: "reset",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				if err := bf.Reset(context.Background()); err != nil {
+					t.Fatalf("Reset() error = %v", err)
+				}
+				mustAdd(t, bf, "ghi789")
+			},
+			want: false,
+		},
+		{
+			name: "promoted staging not yet marked",
+			lose: func(t *testing.T, server *miniredis.Miniredis, bf *BloomFilter) {
+				ctx := context.Background()
+				staging := bf.Staging(0)
+				if err := staging.Reset(ctx); err != nil {
+					t.Fatalf("Reset() error = %v", err)
+				}
+				mustAdd(t, staging, "abc123")
+				if err := bf.Promote(ctx, staging); err != nil {
+					t.Fatalf("Promote() error = %v", err)
+				}
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, redisClient := newTestRedis(t)
+
+			// miniredis 不支持 BF.* 命令，自动探测应退回位图后端
+			bf := NewBloomFilter(redisClient, &config.BloomFilterConfig{Backend: BloomBackendAuto, Key: "test", Capacity: 1000, ErrorRate: 0.01})
+			if err := bf.Initialize(ctx); err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			if bf.Backend() != BloomBackendBitmap {
+				t.Fatalf("Backend() = %q, want %q", bf.Backend(), BloomBackendBitmap)
+			}
+
+			mustAdd(t, bf, "abc123")
+			mustAdd(t, bf, "def456")
+			if populated, err := bf.Populated(ctx); err != nil || populated {
+				t.Fatalf("Populated() before marking = %v, %v; want false", populated, err)
+			}
+			if err := bf.MarkPopulated(ctx); err != nil {
+				t.Fatalf("MarkPopulated() error = %v", err)
+			}
+
+			tt.lose(t, server, bf)
+
+			populated, err := bf.Populated(ctx)
+			if err != nil {
+				t.Fatalf("Populated() error = %v", err)
+			}
+			if populated != tt.want {
+				t.Errorf("Populated() = %v, want %v", populated, tt.want)
+			}
+		})
+	}
+}
+
+func mustAdd(t *testing.T, bf *BloomFilter, item string) {
+	t.Helper()
+	if err := bf.Add(context.Background(), item); err != nil {
+		t.Fatalf("Add(%q) error = %v", item, err)
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 缓存后端类型
+const (
+	CacheDriverRedis  = "redis"
+	CacheDriverMemory = "memory"
+)
+
+// ErrCacheMiss 缓存未命中，与 redis.Nil 保持一致以兼容现有判断
+var ErrCacheMiss = redis.Nil
+
+// Entry 批量写入的缓存条目
+type Entry struct {
+	Key        string
+	Value      string
+	Expiration time.Duration
+}
+
+// Cache 键值缓存接口
+type Cache interface {
+	// Get 获取缓存值，未命中时返回 ErrCacheMiss
+	Get(ctx context.Context, key string) (string, error)
+	// Set 写入缓存值，expiration 为 0 表示永不过期
+	Set(ctx context.Context, key, value string, expiration time.Duration) error
+	// SetWithDefaultTTL 使用默认 TTL 写入缓存值
+	SetWithDefaultTTL(ctx context.Context, key, value string) error
+	// SetMulti 批量写入缓存值
+	SetMulti(ctx context.Context, entries []Entry) error
+	// Delete 删除缓存值
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	_ Cache = (*RedisClient)(nil)
+	_ Cache = (*LRUCache)(nil)
+	_ Cache = (*TieredCache)(nil)
+)
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"short-url/internal/config"
+	"sync"
+	"time"
+)
+
+const defaultLRUMaxEntries = 100000
+
+// LRUCache 进程内的 LRU 缓存，支持按条目 TTL 过期
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func NewLRUCache(cacheConfig *config.CacheConfig) *LRUCache {
+	return newLRUCache(cacheConfig.MaxEntries, cacheConfig.TTL)
+}
+
+func newLRUCache(maxEntries int, ttl time.Duration) *LRUCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultLRUMaxEntries
+	}
+
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return "", ErrCacheMiss
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if entry.expired(time.Now()) {
+		c.removeElement(elem)
+		return "", ErrCacheMiss
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry.value, nil
+}
+
+func (c *LRUCache) Set(ctx context.Context, key, value string, expiration time.Duration) error {
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	// 超出容量时淘汰最久未使用的条目
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+
+	return nil
+}
+
+func (c *LRUCache) SetWithDefaultTTL(ctx context.Context, key, value string) error {
+	return c.Set(ctx, key, value, c.ttl)
+}
+
+func (c *LRUCache) SetMulti(ctx context.Context, entries []Entry) error {
+	for _, entry := range entries {
+		c.Set(ctx, entry.Key, entry.Value, entry.Expiration)
+	}
+	return nil
+}
+
+func (c *LRUCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	return nil
+}
+
+// Len 返回当前缓存条目数（包含尚未被惰性清理的过期条目）
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *LRUCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).key)
+}
+
+func (e *lruEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
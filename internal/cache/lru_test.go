@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		// ops 依次执行，"set:k" 写入 k，"get:k" 读取 k，"del:k" 删除 k
+		ops     []string
+		present []string
+		absent  []string
+	}{
+		{
+			name:       "evicts least recently set",
+			maxEntries: 2,
+			ops:        []string{"set:a", "set:b", "set:c"},
+			present:    []string{"b", "c"},
+			absent:     []string{"a"},
+		},
+		{
+			name:       "get refreshes recency",
+			maxEntries: 2,
+			ops:        []string{"set:a", "set:b", "get:a", "set:c"},
+			present:    []string{"a", "c"},
+			absent:     []string{"b"},
+		},
+		{
+			name:       "overwrite refreshes recency without growing",
+			maxEntries: 2,
+			ops:        []string{"set:a", "set:b", "set:a", "set:c"},
+			present:    []string{"a", "c"},
+			absent:     []string{"b"},
+		},
+		{
+			name:       "delete frees a slot",
+			maxEntries: 2,
+			ops:        []string{"set:a", "set:b", "del:a", "set:c"},
+			present:    []string{"b", "c"},
+			absent:     []string{"a"},
+		},
+		{
+			name:       "non-positive size uses default",
+			maxEntries: 0,
+			ops:        []string{"set:a", "set:b", "set:c"},
+			present:    []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newLRUCache(tt.maxEntries, 0)
+
+			for _, op := range tt.ops {
+				action, key := op[:3], op[4:]
+				switch action {
+				case "set":
+					c.Set(ctx, key, "value-"+key, 0)
+				case "get":
+					c.Get(ctx, key)
+				case "del":
+					c.Delete(ctx, key)
+				default:
+					t.Fatalf("unknown op %q", op)
+				}
+			}
+
+			for _, key := range tt.present {
+				value, err := c.Get(ctx, key)
+				if err != nil || value != "value-"+key {
+					t.Errorf("Get(%q) = %q, %v; want %q", key, value, err, "value-"+key)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, err := c.Get(ctx, key); !errors.Is(err, ErrCacheMiss) {
+					t.Errorf("Get(%q) error = %v; want ErrCacheMiss", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		wantHit   bool
+	}{
+		{name: "no expiry", expiresAt: time.Time{}, wantHit: true},
+		{name: "not yet expired", expiresAt: now.Add(time.Minute), wantHit: true},
+		{name: "expired", expiresAt: now.Add(-time.Millisecond), wantHit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newLRUCache(10, 0)
+			c.Set(ctx, "k", "v", 0)
+			c.items["k"].Value.(*lruEntry).expiresAt = tt.expiresAt
+
+			_, err := c.Get(ctx, "k")
+			if hit := err == nil; hit != tt.wantHit {
+				t.Fatalf("Get hit = %v, want %v (err %v)", hit, tt.wantHit, err)
+			}
+			// 过期条目在读取时被清理
+			if !tt.wantHit && c.Len() != 0 {
+				t.Errorf("Len() = %d after reading expired entry, want 0", c.Len())
+			}
+		})
+	}
+}
+
+func TestLRUCacheSetExpiration(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiration time.Duration
+		defaultTTL time.Duration
+		useDefault bool
+		wantZero   bool
+	}{
+		{name: "explicit expiration", expiration: time.Minute, wantZero: false},
+		{name: "zero never expires", expiration: 0, wantZero: true},
+		{name: "default ttl", defaultTTL: time.Minute, useDefault: true, wantZero: false},
+		{name: "zero default ttl never expires", useDefault: true, wantZero: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newLRUCache(10, tt.defaultTTL)
+			if tt.useDefault {
+				c.SetWithDefaultTTL(ctx, "k", "v")
+			} else {
+				c.Set(ctx, "k", "v", tt.expiration)
+			}
+
+			expiresAt := c.items["k"].Value.(*lruEntry).expiresAt
+			if expiresAt.IsZero() != tt.wantZero {
+				t.Errorf("expiresAt = %v, want zero = %v", expiresAt, tt.wantZero)
+			}
+		})
+	}
+}
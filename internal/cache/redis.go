@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"short-url/internal/config"
+	"short-url/internal/tracing"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -19,6 +20,8 @@ func NewRedisClient(redisConfig *config.RedisConfig, cacheConfig *config.CacheCo
 		Password: redisConfig.Password,
 		DB:       redisConfig.DB,
 	})
+	// 每条命令和 pipeline 创建一个 span，包括 BF.* 命令和 Lua 脚本
+	rdb.AddHook(tracing.RedisHook{})
 
 	return &RedisClient{
 		client: rdb,
@@ -30,6 +33,26 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
 
+// GetWithTTL 在一次往返中读取值及其剩余 TTL，键没有过期时间时 TTL 为 0
+func (r *RedisClient) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	ttl := pttl.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return get.Val(), ttl, nil
+}
+
 func (r *RedisClient) Set(ctx context.Context, key, value string, expiration time.Duration) error {
 	return r.client.Set(ctx, key, value, expiration).Err()
 }
@@ -38,6 +61,21 @@ func (r *RedisClient) SetWithDefaultTTL(ctx context.Context, key, value string)
 	return r.Set(ctx, key, value, r.config.TTL)
 }
 
+// SetMulti 通过 pipeline 批量写入，只需一次往返
+func (r *RedisClient) SetMulti(ctx context.Context, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, entry := range entries {
+			pipe.Set(ctx, entry.Key, entry.Value, entry.Expiration)
+		}
+		return nil
+	})
+	return err
+}
+
 func (r *RedisClient) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"net"
+	"short-url/internal/config"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis 启动进程内的 miniredis，返回服务端和连接到它的客户端
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *RedisClient) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	host, port, err := net.SplitHostPort(server.Addr())
+	if err != nil {
+		t.Fatalf("SplitHostPort(%q) error = %v", server.Addr(), err)
+	}
+	portNumber, _ := strconv.Atoi(port)
+
+	client := NewRedisClient(&config.RedisConfig{Host: host, Port: portNumber}, &config.CacheConfig{})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"short-url/internal/config"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultL1MaxEntries        = 10000
+	defaultL1TTL               = 30 * time.Second
+	defaultInvalidationChannel = "shorturl:cache:invalidate"
+)
+
+// TieredCache 两级缓存：进程内 L1 LRU 在前，Redis 在后。
+// 删除会通过 Redis pub/sub 广播失效消息，其他实例收到后淘汰各自 L1 中的旧条目；
+// 写入只是回填，不广播，修改数据（包括创建此前不存在、可能已有负缓存的键）的一方应先删除再写入或由读取回填。
+// 订阅断线期间错过的消息由较短的 L1 TTL 兜底。
+type TieredCache struct {
+	l1         *LRUCache
+	l2         *RedisClient
+	l1TTL      time.Duration
+	channel    string
+	instanceID string
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+func NewTieredCache(redisClient *RedisClient, cacheConfig *config.CacheConfig) (*TieredCache, error) {
+	l1TTL := cacheConfig.L1TTL
+	if l1TTL <= 0 {
+		l1TTL = defaultL1TTL
+	}
+	maxEntries := cacheConfig.L1MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultL1MaxEntries
+	}
+	channel := cacheConfig.InvalidationChannel
+	if channel == "" {
+		channel = defaultInvalidationChannel
+	}
+
+	instanceID, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TieredCache{
+		l1:         newLRUCache(maxEntries, l1TTL),
+		l2:         redisClient,
+		l1TTL:      l1TTL,
+		channel:    channel,
+		instanceID: instanceID,
+	}, nil
+}
+
+// Start 订阅失效频道，开始处理其他实例的失效消息
+func (t *TieredCache) Start(ctx context.Context) error {
+	t.pubsub = t.l2.GetClient().Subscribe(ctx, t.channel)
+	if _, err := t.pubsub.Receive(ctx); err != nil {
+		t.pubsub.Close()
+		return err
+	}
+
+	t.done = make(chan struct{})
+	go t.listen()
+	return nil
+}
+
+// Close 取消订阅
+func (t *TieredCache) Close() error {
+	if t.pubsub == nil {
+		return nil
+	}
+	err := t.pubsub.Close()
+	<-t.done
+	return err
+}
+
+func (t *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, err := t.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	// L1 条目不应比 L2 中的剩余 TTL 存活更久，如即将过期的链接和负缓存
+	value, ttl, err := t.l2.GetWithTTL(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	t.l1.Set(ctx, key, value, t.l1Expiration(ttl))
+	return value, nil
+}
+
+func (t *TieredCache) Set(ctx context.Context, key, value string, expiration time.Duration) error {
+	if err := t.l2.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+
+	t.l1.Set(ctx, key, value, t.l1Expiration(expiration))
+	return nil
+}
+
+func (t *TieredCache) SetWithDefaultTTL(ctx context.Context, key, value string) error {
+	return t.Set(ctx, key, value, t.l2.config.TTL)
+}
+
+func (t *TieredCache) SetMulti(ctx context.Context, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := t.l2.SetMulti(ctx, entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		t.l1.Set(ctx, entry.Key, entry.Value, t.l1Expiration(entry.Expiration))
+	}
+	return nil
+}
+
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	t.l1.Delete(ctx, key)
+	if err := t.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	return t.publishInvalidation(ctx, key)
+}
+
+// l1Expiration 取 L1 TTL 与 L2 过期时间的较小值，expiration 为 0 表示 L2 永不过期
+func (t *TieredCache) l1Expiration(expiration time.Duration) time.Duration {
+	if expiration > 0 && expiration < t.l1TTL {
+		return expiration
+	}
+	return t.l1TTL
+}
+
+// publishInvalidation 广播失效消息，格式为 <instanceID>|<key>
+func (t *TieredCache) publishInvalidation(ctx context.Context, key string) error {
+	return t.l2.GetClient().Publish(ctx, t.channel, t.instanceID+"|"+key).Err()
+}
+
+func (t *TieredCache) listen() {
+	defer close(t.done)
+
+	for msg := range t.pubsub.Channel() {
+		instanceID, key, ok := strings.Cut(msg.Payload, "|")
+		if !ok || instanceID == t.instanceID {
+			continue
+		}
+		t.l1.Delete(context.Background(), key)
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"short-url/internal/config"
+	"testing"
+	"time"
+)
+
+// newTestTieredCaches 创建共享同一个 Redis 的两个实例并订阅失效频道
+func newTestTieredCaches(t *testing.T) (*TieredCache, *TieredCache) {
+	t.Helper()
+
+	_, client := newTestRedis(t)
+	caches := make([]*TieredCache, 2)
+	for i := range caches {
+		tiered, err := NewTieredCache(client, &config.CacheConfig{L1TTL: time.Hour})
+		if err != nil {
+			t.Fatalf("NewTieredCache() error = %v", err)
+		}
+		if err := tiered.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		t.Cleanup(func() { tiered.Close() })
+		caches[i] = tiered
+	}
+	return caches[0], caches[1]
+}
+
+// waitForL1 等待 L1 中 key 的状态变为 want（true 表示存在），失效消息是异步送达的
+func waitForL1(t *testing.T, tiered *TieredCache, key string, want bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, err := tiered.l1.Get(context.Background(), key)
+		if (err == nil) == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("L1 contains %q = %v, want %v", key, err == nil, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTieredCacheInvalidation(t *testing.T) {
+	const key = "shorturl:abc123"
+
+	tests := []struct {
+		name string
+		// write 在实例 a 上修改 key，此前两个实例的 L1 都已缓存旧值
+		write      func(ctx context.Context, a *TieredCache) error
+		wantRemote bool
+		wantLocal  bool
+	}{
+		{
+			name:       "delete evicts other instances",
+			write:      func(ctx context.Context, a *TieredCache) error { return a.Delete(ctx, key) },
+			wantRemote: false,
+			wantLocal:  false,
+		},
+		{
+			name:       "set is a fill and does not broadcast",
+			write:      func(ctx context.Context, a *TieredCache) error { return a.Set(ctx, key, "new", time.Hour) },
+			wantRemote: true,
+			wantLocal:  true,
+		},
+		{
+			name: "delete then set replaces stale entries",
+			write: func(ctx context.Context, a *TieredCache) error {
+				if err := a.Delete(ctx, key); err != nil {
+					return err
+				}
+				return a.Set(ctx, key, "new", time.Hour)
+			},
+			wantRemote: false,
+			wantLocal:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			a, b := newTestTieredCaches(t)
+
+			if err := a.Set(ctx, key, "old", time.Hour); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if value, err := b.Get(ctx, key); err != nil || value != "old" {
+				t.Fatalf("Get() = %q, %v; want old", value, err)
+			}
+
+			if err := tt.write(ctx, a); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			if !tt.wantRemote {
+				waitForL1(t, b, key, false)
+			} else {
+				// 给可能的失效消息留出送达时间
+				time.Sleep(50 * time.Millisecond)
+				waitForL1(t, b, key, true)
+			}
+			if _, err := a.l1.Get(ctx, key); (err == nil) != tt.wantLocal {
+				t.Errorf("local L1 contains %q = %v, want %v", key, err == nil, tt.wantLocal)
+			}
+		})
+	}
+}
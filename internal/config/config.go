@@ -2,18 +2,30 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	Storage     StorageConfig     `mapstructure:"storage"`
 	Database    DatabaseConfig    `mapstructure:"database"`
 	Redis       RedisConfig       `mapstructure:"redis"`
 	App         AppConfig         `mapstructure:"app"`
 	BloomFilter BloomFilterConfig `mapstructure:"bloom_filter"`
 	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
 	Cache       CacheConfig       `mapstructure:"cache"`
+	AccessCount AccessCountConfig `mapstructure:"access_count"`
+	Analytics   AnalyticsConfig   `mapstructure:"analytics"`
+	Stats       StatsConfig       `mapstructure:"stats"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Debug       DebugConfig       `mapstructure:"debug"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+}
+
+type StorageConfig struct {
+	Driver string `mapstructure:"driver"`
 }
 
 type DatabaseConfig struct {
@@ -33,24 +45,94 @@ type RedisConfig struct {
 }
 
 type AppConfig struct {
-	Port    int    `mapstructure:"port"`
-	Env     string `mapstructure:"env"`
-	BaseURL string `mapstructure:"base_url"`
+	Port           int      `mapstructure:"port"`
+	Env            string   `mapstructure:"env"`
+	BaseURL        string   `mapstructure:"base_url"`
+	BatchMaxItems  int      `mapstructure:"batch_max_items"`
+	AdminToken     string   `mapstructure:"admin_token"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	HealthCheckTimeout time.Duration `mapstructure:"health_check_timeout"`
+	ShutdownDrainDelay time.Duration `mapstructure:"shutdown_drain_delay"`
 }
 
 type BloomFilterConfig struct {
-	Key       string  `mapstructure:"key"`
-	Capacity  int     `mapstructure:"capacity"`
-	ErrorRate float64 `mapstructure:"error_rate"`
+	Backend         string        `mapstructure:"backend"`
+	Key             string        `mapstructure:"key"`
+	Capacity        int           `mapstructure:"capacity"`
+	ErrorRate       float64       `mapstructure:"error_rate"`
+	SyncBatchSize   int           `mapstructure:"sync_batch_size"`
+	MonitorInterval time.Duration `mapstructure:"monitor_interval"`
+	RotateThreshold float64       `mapstructure:"rotate_threshold"`
+	GrowthFactor    float64       `mapstructure:"growth_factor"`
 }
 
 type RateLimitConfig struct {
-	Requests int           `mapstructure:"requests"`
-	Window   time.Duration `mapstructure:"window"`
+	Enabled          bool          `mapstructure:"enabled"`
+	Backend          string        `mapstructure:"backend"`
+	Requests         int           `mapstructure:"requests"`
+	Window           time.Duration `mapstructure:"window"`
+	CreateRequests   int           `mapstructure:"create_requests"`
+	RedirectRequests int           `mapstructure:"redirect_requests"`
+	AdminRequests    int           `mapstructure:"admin_requests"`
+	AuthRequests     int           `mapstructure:"auth_requests"`
+	FallbackCooldown time.Duration `mapstructure:"fallback_cooldown"`
 }
 
 type CacheConfig struct {
-	TTL time.Duration `mapstructure:"ttl"`
+	Driver              string        `mapstructure:"driver"`
+	TTL                 time.Duration `mapstructure:"ttl"`
+	MaxEntries          int           `mapstructure:"max_entries"`
+	NegativeTTL         time.Duration `mapstructure:"negative_ttl"`
+	L1Enabled           bool          `mapstructure:"l1_enabled"`
+	L1MaxEntries        int           `mapstructure:"l1_max_entries"`
+	L1TTL               time.Duration `mapstructure:"l1_ttl"`
+	InvalidationChannel string        `mapstructure:"invalidation_channel"`
+}
+
+type AccessCountConfig struct {
+	Backend       string        `mapstructure:"backend"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+}
+
+type AnalyticsConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	BufferSize    int           `mapstructure:"buffer_size"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	AnonymizeIP   bool          `mapstructure:"anonymize_ip"`
+
+	VisitorBackend        string        `mapstructure:"visitor_backend"`
+	VisitorTTL            time.Duration `mapstructure:"visitor_ttl"`
+	VisitorRollupInterval time.Duration `mapstructure:"visitor_rollup_interval"`
+
+	RollupInterval time.Duration `mapstructure:"rollup_interval"`
+	RollupDelay    time.Duration `mapstructure:"rollup_delay"`
+	RawRetention   time.Duration `mapstructure:"raw_retention"`
+}
+
+type StatsConfig struct {
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	CacheTTL        time.Duration `mapstructure:"cache_ttl"`
+}
+
+type TracingConfig struct {
+	Exporter     string  `mapstructure:"exporter"`
+	ServiceName  string  `mapstructure:"service_name"`
+	SampleRatio  float64 `mapstructure:"sample_ratio"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
+	OTLPInsecure bool    `mapstructure:"otlp_insecure"`
+	FilePath     string  `mapstructure:"file_path"`
+}
+
+type DebugConfig struct {
+	Addr string `mapstructure:"addr"`
+}
+
+type AuthConfig struct {
+	RequireAPIKey bool          `mapstructure:"require_api_key"`
+	CacheTTL      time.Duration `mapstructure:"cache_ttl"`
 }
 
 func Load() (*Config, error) {
@@ -71,6 +153,13 @@ func Load() (*Config, error) {
 		fmt.Printf("Config file not found, using environment variables: %v\n", err)
 	}
 
+	// 配置文件以环境变量名为键，映射到对应配置项，优先级高于默认值、低于环境变量
+	for env, key := range envKeys {
+		if name := strings.ToLower(env); viper.InConfig(name) {
+			viper.SetDefault(key, viper.Get(name))
+		}
+	}
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
@@ -80,62 +169,190 @@ func Load() (*Config, error) {
 }
 
 func setDefaults() {
+	// Storage defaults
+	viper.SetDefault("storage.driver", "postgres")
+
 	// Database defaults
-	viper.SetDefault("DB_HOST", "localhost")
-	viper.SetDefault("DB_PORT", 5432)
-	viper.SetDefault("DB_USER", "postgres")
-	viper.SetDefault("DB_PASSWORD", "password")
-	viper.SetDefault("DB_NAME", "shorturl")
-	viper.SetDefault("DB_SSLMODE", "disable")
+	viper.SetDefault("database.host", "localhost")
+	viper.SetDefault("database.port", 5432)
+	viper.SetDefault("database.user", "postgres")
+	viper.SetDefault("database.password", "password")
+	viper.SetDefault("database.name", "shorturl")
+	viper.SetDefault("database.sslmode", "disable")
 
 	// Redis defaults
-	viper.SetDefault("REDIS_HOST", "localhost")
-	viper.SetDefault("REDIS_PORT", 6379)
-	viper.SetDefault("REDIS_PASSWORD", "")
-	viper.SetDefault("REDIS_DB", 0)
+	viper.SetDefault("redis.host", "localhost")
+	viper.SetDefault("redis.port", 6379)
+	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.db", 0)
 
 	// App defaults
-	viper.SetDefault("APP_PORT", 8080)
-	viper.SetDefault("APP_ENV", "development")
-	viper.SetDefault("BASE_URL", "http://localhost:8080")
+	viper.SetDefault("app.port", 8080)
+	viper.SetDefault("app.env", "development")
+	viper.SetDefault("app.base_url", "http://localhost:8080")
+	viper.SetDefault("app.batch_max_items", 1000)
+	viper.SetDefault("app.health_check_timeout", "2s")
+	viper.SetDefault("app.shutdown_drain_delay", "0s")
 
 	// Bloom filter defaults
-	viper.SetDefault("BLOOM_FILTER_KEY", "used_short_codes")
-	viper.SetDefault("BLOOM_FILTER_CAPACITY", 1000000)
-	viper.SetDefault("BLOOM_FILTER_ERROR_RATE", 0.001)
+	viper.SetDefault("bloom_filter.backend", "auto")
+	viper.SetDefault("bloom_filter.key", "used_short_codes")
+	viper.SetDefault("bloom_filter.capacity", 1000000)
+	viper.SetDefault("bloom_filter.error_rate", 0.001)
+	viper.SetDefault("bloom_filter.sync_batch_size", 5000)
+	viper.SetDefault("bloom_filter.monitor_interval", "60s")
+	viper.SetDefault("bloom_filter.rotate_threshold", 0.9)
+	viper.SetDefault("bloom_filter.growth_factor", 2.0)
 
 	// Rate limit defaults
-	viper.SetDefault("RATE_LIMIT_REQUESTS", 100)
-	viper.SetDefault("RATE_LIMIT_WINDOW", "60s")
+	viper.SetDefault("rate_limit.enabled", false)
+	viper.SetDefault("rate_limit.backend", "redis")
+	viper.SetDefault("rate_limit.requests", 100)
+	viper.SetDefault("rate_limit.window", "60s")
+	viper.SetDefault("rate_limit.create_requests", 20)
+	viper.SetDefault("rate_limit.redirect_requests", 600)
+	viper.SetDefault("rate_limit.admin_requests", 30)
+	viper.SetDefault("rate_limit.auth_requests", 300)
+	viper.SetDefault("rate_limit.fallback_cooldown", "5s")
 
 	// Cache defaults
-	viper.SetDefault("CACHE_TTL", "3600s")
+	viper.SetDefault("cache.driver", "redis")
+	viper.SetDefault("cache.ttl", "3600s")
+	viper.SetDefault("cache.max_entries", 100000)
+	viper.SetDefault("cache.negative_ttl", "30s")
+	viper.SetDefault("cache.l1_enabled", false)
+	viper.SetDefault("cache.l1_max_entries", 10000)
+	viper.SetDefault("cache.l1_ttl", "30s")
+	viper.SetDefault("cache.invalidation_channel", "shorturl:cache:invalidate")
+
+	// Access count defaults
+	viper.SetDefault("access_count.backend", "redis")
+	viper.SetDefault("access_count.flush_interval", "5s")
+	viper.SetDefault("access_count.batch_size", 1000)
+
+	// Analytics defaults
+	viper.SetDefault("analytics.enabled", true)
+	viper.SetDefault("analytics.buffer_size", 10000)
+	viper.SetDefault("analytics.batch_size", 500)
+	viper.SetDefault("analytics.flush_interval", "2s")
+	viper.SetDefault("analytics.anonymize_ip", false)
+	viper.SetDefault("analytics.visitor_backend", "redis")
+	viper.SetDefault("analytics.visitor_ttl", "840h")
+	viper.SetDefault("analytics.visitor_rollup_interval", "5m")
+	viper.SetDefault("analytics.rollup_interval", "1m")
+	viper.SetDefault("analytics.rollup_delay", "5m")
+	viper.SetDefault("analytics.raw_retention", "2160h")
+
+	// Stats defaults
+	viper.SetDefault("stats.refresh_interval", "5m")
+	viper.SetDefault("stats.cache_ttl", "1m")
+
+	// Tracing defaults
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.service_name", "short-url")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.otlp_endpoint", "localhost:4318")
+	viper.SetDefault("tracing.otlp_insecure", false)
+	viper.SetDefault("tracing.file_path", "traces.jsonl")
+
+	// Debug defaults
+	viper.SetDefault("debug.addr", "")
+
+	// Auth defaults
+	viper.SetDefault("auth.require_api_key", false)
+	viper.SetDefault("auth.cache_ttl", "1m")
 
 	// Bind environment variables
-	viper.BindEnv("database.host", "DB_HOST")
-	viper.BindEnv("database.port", "DB_PORT")
-	viper.BindEnv("database.user", "DB_USER")
-	viper.BindEnv("database.password", "DB_PASSWORD")
-	viper.BindEnv("database.name", "DB_NAME")
-	viper.BindEnv("database.sslmode", "DB_SSLMODE")
-
-	viper.BindEnv("redis.host", "REDIS_HOST")
-	viper.BindEnv("redis.port", "REDIS_PORT")
-	viper.BindEnv("redis.password", "REDIS_PASSWORD")
-	viper.BindEnv("redis.db", "REDIS_DB")
-
-	viper.BindEnv("app.port", "APP_PORT")
-	viper.BindEnv("app.env", "APP_ENV")
-	viper.BindEnv("app.base_url", "BASE_URL")
-
-	viper.BindEnv("bloom_filter.key", "BLOOM_FILTER_KEY")
-	viper.BindEnv("bloom_filter.capacity", "BLOOM_FILTER_CAPACITY")
-	viper.BindEnv("bloom_filter.error_rate", "BLOOM_FILTER_ERROR_RATE")
-
-	viper.BindEnv("rate_limit.requests", "RATE_LIMIT_REQUESTS")
-	viper.BindEnv("rate_limit.window", "RATE_LIMIT_WINDOW")
-
-	viper.BindEnv("cache.ttl", "CACHE_TTL")
+	bindEnv("storage.driver", "STORAGE_DRIVER")
+
+	bindEnv("database.host", "DB_HOST")
+	bindEnv("database.port", "DB_PORT")
+	bindEnv("database.user", "DB_USER")
+	bindEnv("database.password", "DB_PASSWORD")
+	bindEnv("database.name", "DB_NAME")
+	bindEnv("database.sslmode", "DB_SSLMODE")
+
+	bindEnv("redis.host", "REDIS_HOST")
+	bindEnv("redis.port", "REDIS_PORT")
+	bindEnv("redis.password", "REDIS_PASSWORD")
+	bindEnv("redis.db", "REDIS_DB")
+
+	bindEnv("app.port", "APP_PORT")
+	bindEnv("app.env", "APP_ENV")
+	bindEnv("app.base_url", "BASE_URL")
+	bindEnv("app.batch_max_items", "BATCH_MAX_ITEMS")
+	bindEnv("app.admin_token", "ADMIN_TOKEN")
+	bindEnv("app.trusted_proxies", "TRUSTED_PROXIES")
+	bindEnv("app.health_check_timeout", "HEALTH_CHECK_TIMEOUT")
+	bindEnv("app.shutdown_drain_delay", "SHUTDOWN_DRAIN_DELAY")
+
+	bindEnv("bloom_filter.backend", "BLOOM_FILTER_BACKEND")
+	bindEnv("bloom_filter.key", "BLOOM_FILTER_KEY")
+	bindEnv("bloom_filter.capacity", "BLOOM_FILTER_CAPACITY")
+	bindEnv("bloom_filter.error_rate", "BLOOM_FILTER_ERROR_RATE")
+	bindEnv("bloom_filter.sync_batch_size", "BLOOM_FILTER_SYNC_BATCH_SIZE")
+	bindEnv("bloom_filter.monitor_interval", "BLOOM_FILTER_MONITOR_INTERVAL")
+	bindEnv("bloom_filter.rotate_threshold", "BLOOM_FILTER_ROTATE_THRESHOLD")
+	bindEnv("bloom_filter.growth_factor", "BLOOM_FILTER_GROWTH_FACTOR")
+
+	bindEnv("rate_limit.enabled", "RATE_LIMIT_ENABLED")
+	bindEnv("rate_limit.backend", "RATE_LIMIT_BACKEND")
+	bindEnv("rate_limit.requests", "RATE_LIMIT_REQUESTS")
+	bindEnv("rate_limit.window", "RATE_LIMIT_WINDOW")
+	bindEnv("rate_limit.create_requests", "RATE_LIMIT_CREATE_REQUESTS")
+	bindEnv("rate_limit.redirect_requests", "RATE_LIMIT_REDIRECT_REQUESTS")
+	bindEnv("rate_limit.admin_requests", "RATE_LIMIT_ADMIN_REQUESTS")
+	bindEnv("rate_limit.auth_requests", "RATE_LIMIT_AUTH_REQUESTS")
+	bindEnv("rate_limit.fallback_cooldown", "RATE_LIMIT_FALLBACK_COOLDOWN")
+
+	bindEnv("cache.driver", "CACHE_DRIVER")
+	bindEnv("cache.ttl", "CACHE_TTL")
+	bindEnv("cache.max_entries", "CACHE_MAX_ENTRIES")
+	bindEnv("cache.negative_ttl", "CACHE_NEGATIVE_TTL")
+	bindEnv("cache.l1_enabled", "CACHE_L1_ENABLED")
+	bindEnv("cache.l1_max_entries", "CACHE_L1_MAX_ENTRIES")
+	bindEnv("cache.l1_ttl", "CACHE_L1_TTL")
+	bindEnv("cache.invalidation_channel", "CACHE_INVALIDATION_CHANNEL")
+
+	bindEnv("access_count.backend", "ACCESS_COUNT_BACKEND")
+	bindEnv("access_count.flush_interval", "ACCESS_COUNT_FLUSH_INTERVAL")
+	bindEnv("access_count.batch_size", "ACCESS_COUNT_BATCH_SIZE")
+
+	bindEnv("analytics.enabled", "ANALYTICS_ENABLED")
+	bindEnv("analytics.buffer_size", "ANALYTICS_BUFFER_SIZE")
+	bindEnv("analytics.batch_size", "ANALYTICS_BATCH_SIZE")
+	bindEnv("analytics.flush_interval", "ANALYTICS_FLUSH_INTERVAL")
+	bindEnv("analytics.anonymize_ip", "ANALYTICS_ANONYMIZE_IP")
+	bindEnv("analytics.visitor_backend", "ANALYTICS_VISITOR_BACKEND")
+	bindEnv("analytics.visitor_ttl", "ANALYTICS_VISITOR_TTL")
+	bindEnv("analytics.visitor_rollup_interval", "ANALYTICS_VISITOR_ROLLUP_INTERVAL")
+	bindEnv("analytics.rollup_interval", "ANALYTICS_ROLLUP_INTERVAL")
+	bindEnv("analytics.rollup_delay", "ANALYTICS_ROLLUP_DELAY")
+	bindEnv("analytics.raw_retention", "ANALYTICS_RAW_RETENTION")
+
+	bindEnv("stats.refresh_interval", "STATS_REFRESH_INTERVAL")
+	bindEnv("stats.cache_ttl", "STATS_CACHE_TTL")
+
+	bindEnv("tracing.exporter", "TRACING_EXPORTER")
+	bindEnv("tracing.service_name", "TRACING_SERVICE_NAME")
+	bindEnv("tracing.sample_ratio", "TRACING_SAMPLE_RATIO")
+	bindEnv("tracing.otlp_endpoint", "TRACING_OTLP_ENDPOINT")
+	bindEnv("tracing.otlp_insecure", "TRACING_OTLP_INSECURE")
+	bindEnv("tracing.file_path", "TRACING_FILE_PATH")
+
+	bindEnv("debug.addr", "DEBUG_ADDR")
+
+	bindEnv("auth.require_api_key", "AUTH_REQUIRE_API_KEY")
+	bindEnv("auth.cache_ttl", "AUTH_CACHE_TTL")
+}
+
+// envKeys 环境变量名到配置项的映射
+var envKeys = make(map[string]string)
+
+// bindEnv 将环境变量绑定到配置项，默认值须设置在配置项上才会生效
+func bindEnv(key, env string) {
+	viper.BindEnv(key, env)
+	envKeys[env] = key
 }
 
 func (d *DatabaseConfig) DSN() string {
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadRateLimitDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantEnabled bool
+		wantBackend string
+		wantWindow  time.Duration
+	}{
+		{name: "disabled by default", wantEnabled: false, wantBackend: "redis", wantWindow: time.Minute},
+		{name: "enabled by env", env: map[string]string{"RATE_LIMIT_ENABLED": "true"}, wantEnabled: true, wantBackend: "redis", wantWindow: time.Minute},
+		{
+			name:        "env overrides defaults",
+			env:         map[string]string{"RATE_LIMIT_ENABLED": "true", "RATE_LIMIT_BACKEND": "memory", "RATE_LIMIT_WINDOW": "10s"},
+			wantEnabled: true,
+			wantBackend: "memory",
+			wantWindow:  10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.RateLimit.Enabled != tt.wantEnabled {
+				t.Errorf("RateLimit.Enabled = %v, want %v", cfg.RateLimit.Enabled, tt.wantEnabled)
+			}
+			if cfg.RateLimit.Backend != tt.wantBackend {
+				t.Errorf("RateLimit.Backend = %q, want %q", cfg.RateLimit.Backend, tt.wantBackend)
+			}
+			if cfg.RateLimit.Window != tt.wantWindow {
+				t.Errorf("RateLimit.Window = %v, want %v", cfg.RateLimit.Window, tt.wantWindow)
+			}
+		})
+	}
+}
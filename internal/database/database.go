@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"short-url/internal/config"
+	"short-url/internal/tracing"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -13,7 +14,14 @@ type DB struct {
 }
 
 func New(cfg *config.DatabaseConfig) (*DB, error) {
-	pool, err := pgxpool.New(context.Background(), cfg.DSN())
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config: %w", err)
+	}
+	// 每条查询创建一个 span，未启用追踪时为空操作
+	poolConfig.ConnConfig.Tracer = tracing.PgxTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
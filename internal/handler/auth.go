@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"short-url/internal/models"
+	"short-url/internal/service"
+	"short-url/pkg/logger"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// apiKeyContextKey 认证通过的 API key 在 gin.Context 中的键
+const apiKeyContextKey = "api_key"
+
+// Authenticate 认证中间件：请求携带 Authorization: Bearer <API key> 时校验 key，无效时返回 401；
+// 不携带时作为匿名请求继续处理，是否必须认证由 RequireScope 决定
+func (h *Handler) Authenticate(c *gin.Context) {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		c.Next()
+		return
+	}
+
+	rawKey, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		respondUnauthorized(c, "invalid_request", "authorization header must use the Bearer scheme")
+		return
+	}
+
+	key, err := h.apiKeys.Authenticate(c.Request.Context(), strings.TrimSpace(rawKey))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			respondUnauthorized(c, "invalid_token", "invalid or revoked API key")
+			return
+		}
+		h.loggerFor(c).Error("failed to authenticate API key", zap.Error(err))
+		respondWithError(c, http.StatusServiceUnavailable, "failed to authenticate API key")
+		c.Abort()
+		return
+	}
+
+	subject := key.Name
+	if key.ID > 0 {
+		subject = strconv.FormatInt(key.ID, 10)
+	}
+	c.Set(apiKeyContextKey, key)
+	c.Set(RateLimitSubjectKey, subject)
+
+	// 后续日志带上调用方
+	ctx := c.Request.Context()
+	ctx = logger.NewContext(ctx, h.loggerFor(c).With(zap.String("api_key", subject)))
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// RequireScope 要求请求已使用具有 scope 权限的 API key 认证，未认证返回 401，权限不足返回 403
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(apiKeyContextKey)
+		key, _ := value.(*models.APIKey)
+		if !exists || key == nil {
+			respondUnauthorized(c, "", "API key required")
+			return
+		}
+		if !key.HasScope(scope) {
+			respondWithError(c, http.StatusForbidden, "API key lacks the "+scope+" scope")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// respondUnauthorized 返回 401 并附带 WWW-Authenticate 头
+func respondUnauthorized(c *gin.Context, errorCode, message string) {
+	challenge := `Bearer realm="api"`
+	if errorCode != "" {
+		challenge += `, error="` + errorCode + `"`
+	}
+	c.Header("WWW-Authenticate", challenge)
+	respondWithError(c, http.StatusUnauthorized, message)
+	c.Abort()
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"runtime/metrics"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runtimeMetricDescriptions 进程内支持的全部 runtime/metrics 指标
+var runtimeMetricDescriptions = metrics.All()
+
+// runtimeSummaryMetrics 摘要中的常用指标
+var runtimeSummaryMetrics = map[string]string{
+	"heap_objects_bytes": "/memory/classes/heap/objects:bytes",
+	"heap_goal_bytes":    "/gc/heap/goal:bytes",
+	"total_memory_bytes": "/memory/classes/total:bytes",
+	"goroutines":         "/sched/goroutines:goroutines",
+	"gc_cycles":          "/gc/cycles/total:gc-cycles",
+}
+
+// histogramSummary 直方图指标的近似分位数，取所在桶的边界
+type histogramSummary struct {
+	Count uint64  `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// RuntimeMetrics 运行时指标接口（调试接口），只读取 runtime/metrics，不触发 GC
+func (h *Handler) RuntimeMetrics(c *gin.Context) {
+	prefix := c.Query("prefix")
+
+	samples := make([]metrics.Sample, 0, len(runtimeMetricDescriptions))
+	for _, desc := range runtimeMetricDescriptions {
+		if strings.HasPrefix(desc.Name, prefix) {
+			samples = append(samples, metrics.Sample{Name: desc.Name})
+		}
+	}
+	for _, name := range runtimeSummaryMetrics {
+		if !strings.HasPrefix(name, prefix) {
+			samples = append(samples, metrics.Sample{Name: name})
+		}
+	}
+	metrics.Read(samples)
+
+	values := make(map[string]interface{}, len(samples))
+	for _, sample := range samples {
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			values[sample.Name] = sample.Value.Uint64()
+		case metrics.KindFloat64:
+			values[sample.Name] = finite(sample.Value.Float64())
+		case metrics.KindFloat64Histogram:
+			values[sample.Name] = summarizeHistogram(sample.Value.Float64Histogram())
+		}
+	}
+
+	summary := make(map[string]interface{}, len(runtimeSummaryMetrics))
+	for key, name := range runtimeSummaryMetrics {
+		summary[key] = values[name]
+		if !strings.HasPrefix(name, prefix) {
+			delete(values, name)
+		}
+	}
+
+	respondWithSuccess(c, http.StatusOK, gin.H{
+		"summary": summary,
+		"metrics": values,
+	})
+}
+
+// summarizeHistogram 计算直方图的样本数和近似分位数
+func summarizeHistogram(hist *metrics.Float64Histogram) histogramSummary {
+	var summary histogramSummary
+	for _, count := range hist.Counts {
+		summary.Count += count
+	}
+	if summary.Count == 0 {
+		return summary
+	}
+
+	quantile := func(q float64) float64 {
+		target := uint64(math.Ceil(q * float64(summary.Count)))
+		var seen uint64
+		for i, count := range hist.Counts {
+			seen += count
+			if seen >= target {
+				return bucketBound(hist.Buckets, i)
+			}
+		}
+		return bucketBound(hist.Buckets, len(hist.Counts)-1)
+	}
+
+	summary.P50 = quantile(0.5)
+	summary.P90 = quantile(0.9)
+	summary.P99 = quantile(0.99)
+	for i := len(hist.Counts) - 1; i >= 0; i-- {
+		if hist.Counts[i] > 0 {
+			summary.Max = bucketBound(hist.Buckets, i)
+			break
+		}
+	}
+	return summary
+}
+
+// bucketBound 返回第 i 个桶的上界，上界为 +Inf 时取下界
+func bucketBound(buckets []float64, i int) float64 {
+	if upper := buckets[i+1]; !math.IsInf(upper, 0) {
+		return upper
+	}
+	return finite(buckets[i])
+}
+
+// finite 将 JSON 无法表示的 NaN 和 Inf 转为 0
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+// Pprof 提供 net/http/pprof 的性能剖析接口（调试接口），路径为 /debug/pprof/*name
+func (h *Handler) Pprof(c *gin.Context) {
+	switch name := strings.TrimPrefix(c.Param("name"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -3,7 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
-	"runtime"
+	"short-url/internal/health"
 	"short-url/internal/models"
 	"short-url/internal/service"
 	"time"
@@ -14,12 +14,16 @@ import (
 
 type Handler struct {
 	shortLinkService *service.ShortLinkService
+	apiKeys          *service.APIKeyService
+	health           *health.Checker
 	logger           *zap.Logger
 }
 
-func NewHandler(shortLinkService *service.ShortLinkService, logger *zap.Logger) *Handler {
+func NewHandler(shortLinkService *service.ShortLinkService, apiKeys *service.APIKeyService, healthChecker *health.Checker, logger *zap.Logger) *Handler {
 	return &Handler{
 		shortLinkService: shortLinkService,
+		apiKeys:          apiKeys,
+		health:           healthChecker,
 		logger:           logger,
 	}
 }
@@ -28,14 +32,14 @@ func NewHandler(shortLinkService *service.ShortLinkService, logger *zap.Logger)
 func (h *Handler) CreateShortLink(c *gin.Context) {
 	var req models.CreateShortLinkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("failed to bind request", zap.Error(err))
+		h.loggerFor(c).Error("failed to bind request", zap.Error(err))
 		respondWithError(c, http.StatusBadRequest, "invalid request format")
 		return
 	}
 
 	response, err := h.shortLinkService.CreateShortLink(c.Request.Context(), &req)
 	if err != nil {
-		h.logger.Error("failed to create short link", zap.Error(err))
+		h.loggerFor(c).Error("failed to create short link", zap.Error(err))
 
 		switch {
 		case errors.Is(err, service.ErrInvalidURL):
@@ -48,9 +52,39 @@ func (h *Handler) CreateShortLink(c *gin.Context) {
 		return
 	}
 
+	if response.Reused {
+		respondWithSuccess(c, http.StatusOK, response, "existing short link reused")
+		return
+	}
+
 	respondWithSuccess(c, http.StatusCreated, response, "short link created successfully")
 }
 
+// BatchCreateShortLinks 批量创建短链接，逐条返回结果
+func (h *Handler) BatchCreateShortLinks(c *gin.Context) {
+	var req models.BatchCreateShortLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.loggerFor(c).Error("failed to bind request", zap.Error(err))
+		respondWithError(c, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	response, err := h.shortLinkService.BatchCreateShortLinks(c.Request.Context(), &req)
+	if err != nil {
+		h.loggerFor(c).Error("failed to create short links", zap.Error(err))
+
+		switch {
+		case errors.Is(err, service.ErrInvalidBatch):
+			respondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(c, http.StatusInternalServerError, "failed to create short links")
+		}
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response, "batch processed")
+}
+
 // RedirectToOriginal 重定向到原始URL
 func (h *Handler) RedirectToOriginal(c *gin.Context) {
 	shortCode := c.Param("code")
@@ -61,19 +95,29 @@ func (h *Handler) RedirectToOriginal(c *gin.Context) {
 
 	originalURL, err := h.shortLinkService.GetOriginalURL(c.Request.Context(), shortCode)
 	if err != nil {
-		h.logger.Error("failed to get original URL", zap.Error(err), zap.String("short_code", shortCode))
+		h.loggerFor(c).Error("failed to get original URL", zap.Error(err), zap.String("short_code", shortCode))
 
 		switch {
 		case errors.Is(err, service.ErrShortCodeNotFound):
 			respondWithError(c, http.StatusNotFound, "short link not found")
 		case errors.Is(err, service.ErrExpiredLink):
 			respondWithError(c, http.StatusGone, "short link has expired")
+		case errors.Is(err, service.ErrLinkDisabled), errors.Is(err, service.ErrLinkDeleted):
+			respondWithError(c, http.StatusGone, err.Error())
 		default:
 			respondWithError(c, http.StatusInternalServerError, "failed to resolve short link")
 		}
 		return
 	}
 
+	h.shortLinkService.RecordClick(&models.ClickEvent{
+		ShortCode:      shortCode,
+		Referrer:       c.Request.Referer(),
+		UserAgent:      c.Request.UserAgent(),
+		ClientIP:       c.ClientIP(),
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+	})
+
 	c.Redirect(http.StatusFound, originalURL)
 }
 
@@ -87,7 +131,7 @@ func (h *Handler) GetShortLinkInfo(c *gin.Context) {
 
 	info, err := h.shortLinkService.GetShortLinkInfo(c.Request.Context(), shortCode)
 	if err != nil {
-		h.logger.Error("failed to get short link info", zap.Error(err), zap.String("short_code", shortCode))
+		h.loggerFor(c).Error("failed to get short link info", zap.Error(err), zap.String("short_code", shortCode))
 
 		switch {
 		case errors.Is(err, service.ErrShortCodeNotFound):
@@ -101,87 +145,260 @@ func (h *Handler) GetShortLinkInfo(c *gin.Context) {
 	respondWithSuccess(c, http.StatusOK, info)
 }
 
+// LookupShortLinks 按原始URL反查短链接
+func (h *Handler) LookupShortLinks(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		respondWithError(c, http.StatusBadRequest, "url is required")
+		return
+	}
+
+	response, err := h.shortLinkService.LookupByURL(c.Request.Context(), rawURL)
+	if err != nil {
+		h.loggerFor(c).Error("failed to lookup short links", zap.Error(err))
+
+		switch {
+		case errors.Is(err, service.ErrInvalidURL):
+			respondWithError(c, http.StatusBadRequest, "invalid URL")
+		default:
+			respondWithError(c, http.StatusInternalServerError, "failed to lookup short links")
+		}
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response)
+}
+
+// ListShortLinks 按条件分页列出短链接
+func (h *Handler) ListShortLinks(c *gin.Context) {
+	var req models.ListShortLinksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.loggerFor(c).Error("failed to bind query", zap.Error(err))
+		respondWithError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	response, err := h.shortLinkService.ListShortLinks(c.Request.Context(), &req)
+	if err != nil {
+		h.loggerFor(c).Error("failed to list short links", zap.Error(err))
+
+		switch {
+		case errors.Is(err, service.ErrInvalidQuery):
+			respondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(c, http.StatusInternalServerError, "failed to list short links")
+		}
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, response)
+}
+
+// GetLinkAnalytics 获取短链接的点击序列和来源、设备分布
+func (h *Handler) GetLinkAnalytics(c *gin.Context) {
+	shortCode := c.Param("code")
+	if shortCode == "" {
+		respondWithError(c, http.StatusBadRequest, "short code is required")
+		return
+	}
+
+	var req models.LinkAnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.loggerFor(c).Error("failed to bind query", zap.Error(err))
+		respondWithError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	analytics, err := h.shortLinkService.GetLinkAnalytics(c.Request.Context(), shortCode, &req)
+	if err != nil {
+		h.loggerFor(c).Error("failed to get link analytics", zap.Error(err), zap.String("short_code", shortCode))
+
+		switch {
+		case errors.Is(err, service.ErrInvalidQuery):
+			respondWithError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrShortCodeNotFound):
+			respondWithError(c, http.StatusNotFound, "short link not found")
+		default:
+			respondWithError(c, http.StatusInternalServerError, "failed to get link analytics")
+		}
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, analytics)
+}
+
+// UpdateShortLink 更新短链接的目标地址或过期时间
+func (h *Handler) UpdateShortLink(c *gin.Context) {
+	shortCode := c.Param("code")
+	if shortCode == "" {
+		respondWithError(c, http.StatusBadRequest, "short code is required")
+		return
+	}
+
+	var req models.UpdateShortLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.loggerFor(c).Error("failed to bind request", zap.Error(err))
+		respondWithError(c, http.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	info, err := h.shortLinkService.UpdateShortLink(c.Request.Context(), shortCode, &req)
+	if err != nil {
+		h.loggerFor(c).Error("failed to update short link", zap.Error(err), zap.String("short_code", shortCode))
+
+		switch {
+		case errors.Is(err, service.ErrInvalidURL):
+			respondWithError(c, http.StatusBadRequest, "invalid URL")
+		case errors.Is(err, service.ErrInvalidUpdate):
+			respondWithError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrShortCodeNotFound):
+			respondWithError(c, http.StatusNotFound, "short link not found")
+		case errors.Is(err, service.ErrLinkDeleted):
+			respondWithError(c, http.StatusGone, "short link has been deleted")
+		default:
+			respondWithError(c, http.StatusInternalServerError, "failed to update short link")
+		}
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, info, "short link updated successfully")
+}
+
+// DeleteShortLink 软删除短链接，短码保留不再分配
+func (h *Handler) DeleteShortLink(c *gin.Context) {
+	shortCode := c.Param("code")
+	if shortCode == "" {
+		respondWithError(c, http.StatusBadRequest, "short code is required")
+		return
+	}
+
+	info, err := h.shortLinkService.DeleteShortLink(c.Request.Context(), shortCode)
+	if err != nil {
+		h.respondWithStatusError(c, err, shortCode, "failed to delete short link")
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, info, "short link deleted successfully")
+}
+
+// DisableShortLink 停用短链接，请求体可选携带停用原因
+func (h *Handler) DisableShortLink(c *gin.Context) {
+	shortCode := c.Param("code")
+	if shortCode == "" {
+		respondWithError(c, http.StatusBadRequest, "short code is required")
+		return
+	}
+
+	var req models.DisableShortLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.loggerFor(c).Error("failed to bind request", zap.Error(err))
+			respondWithError(c, http.StatusBadRequest, "invalid request format")
+			return
+		}
+	}
+
+	info, err := h.shortLinkService.DisableShortLink(c.Request.Context(), shortCode, req.Reason)
+	if err != nil {
+		h.respondWithStatusError(c, err, shortCode, "failed to disable short link")
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, info, "short link disabled successfully")
+}
+
+// EnableShortLink 重新启用已停用的短链接
+func (h *Handler) EnableShortLink(c *gin.Context) {
+	shortCode := c.Param("code")
+	if shortCode == "" {
+		respondWithError(c, http.StatusBadRequest, "short code is required")
+		return
+	}
+
+	info, err := h.shortLinkService.EnableShortLink(c.Request.Context(), shortCode)
+	if err != nil {
+		h.respondWithStatusError(c, err, shortCode, "failed to enable short link")
+		return
+	}
+
+	respondWithSuccess(c, http.StatusOK, info, "short link enabled successfully")
+}
+
+// respondWithStatusError 处理状态变更接口的错误响应
+func (h *Handler) respondWithStatusError(c *gin.Context, err error, shortCode, message string) {
+	h.loggerFor(c).Error(message, zap.Error(err), zap.String("short_code", shortCode))
+
+	switch {
+	case errors.Is(err, service.ErrShortCodeNotFound):
+		respondWithError(c, http.StatusNotFound, "short link not found")
+	case errors.Is(err, service.ErrLinkDeleted):
+		respondWithError(c, http.StatusGone, "short link has been deleted")
+	default:
+		respondWithError(c, http.StatusInternalServerError, message)
+	}
+}
+
 // GetStats 获取统计信息
 func (h *Handler) GetStats(c *gin.Context) {
-	stats, err := h.shortLinkService.GetStats(c.Request.Context())
+	var req models.StatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.loggerFor(c).Error("failed to bind query", zap.Error(err))
+		respondWithError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	stats, err := h.shortLinkService.GetStats(c.Request.Context(), &req)
 	if err != nil {
-		h.logger.Error("failed to get stats", zap.Error(err))
-		respondWithError(c, http.StatusInternalServerError, "failed to get statistics")
+		h.loggerFor(c).Error("failed to get stats", zap.Error(err))
+
+		switch {
+		case errors.Is(err, service.ErrInvalidQuery):
+			respondWithError(c, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(c, http.StatusInternalServerError, "failed to get statistics")
+		}
 		return
 	}
 
 	respondWithSuccess(c, http.StatusOK, stats)
 }
 
-// Health 健康检查
-func (h *Handler) Health(c *gin.Context) {
-	health := Health{
+// Liveness 存活检查，不检查外部依赖
+func (h *Handler) Liveness(c *gin.Context) {
+	respondWithSuccess(c, http.StatusOK, Health{
+		Status:    "ok",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// Readiness 就绪检查，依赖不可用或服务正在停止时返回 503
+func (h *Handler) Readiness(c *gin.Context) {
+	services, healthy := h.health.Check(c.Request.Context())
+	response := Health{
 		Status:    "ok",
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Services:  make(map[string]interface{}),
-	}
-
-	// 这里可以添加对各个服务的健康检查
-	// 例如数据库连接、Redis连接等
-
-	respondWithSuccess(c, http.StatusOK, health)
-}
-
-// MemoryStats 内存统计接口
-func (h *Handler) MemoryStats(c *gin.Context) {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
-	// 手动触发GC
-	runtime.GC()
-
-	// 再次读取内存统计
-	var m2 runtime.MemStats
-	runtime.ReadMemStats(&m2)
-
-	stats := map[string]interface{}{
-		"before_gc": map[string]interface{}{
-			"alloc_mb":       bToMb(m.Alloc),
-			"total_alloc_mb": bToMb(m.TotalAlloc),
-			"sys_mb":         bToMb(m.Sys),
-			"heap_alloc_mb":  bToMb(m.HeapAlloc),
-			"heap_sys_mb":    bToMb(m.HeapSys),
-			"heap_idle_mb":   bToMb(m.HeapIdle),
-			"heap_inuse_mb":  bToMb(m.HeapInuse),
-			"stack_inuse_mb": bToMb(m.StackInuse),
-			"stack_sys_mb":   bToMb(m.StackSys),
-			"num_gc":         m.NumGC,
-			"num_goroutine":  runtime.NumGoroutine(),
-		},
-		"after_gc": map[string]interface{}{
-			"alloc_mb":       bToMb(m2.Alloc),
-			"total_alloc_mb": bToMb(m2.TotalAlloc),
-			"sys_mb":         bToMb(m2.Sys),
-			"heap_alloc_mb":  bToMb(m2.HeapAlloc),
-			"heap_sys_mb":    bToMb(m2.HeapSys),
-			"heap_idle_mb":   bToMb(m2.HeapIdle),
-			"heap_inuse_mb":  bToMb(m2.HeapInuse),
-			"stack_inuse_mb": bToMb(m2.StackInuse),
-			"stack_sys_mb":   bToMb(m2.StackSys),
-			"num_gc":         m2.NumGC,
-			"num_goroutine":  runtime.NumGoroutine(),
-		},
-		"gc_triggered": m2.NumGC > m.NumGC,
+		Services:  services,
 	}
 
-	respondWithSuccess(c, http.StatusOK, stats)
-}
+	switch {
+	case h.health.Draining():
+		response.Status = "draining"
+	case !healthy:
+		response.Status = "unavailable"
+		h.loggerFor(c).Warn("readiness check failed", zap.Any("services", services))
+	default:
+		respondWithSuccess(c, http.StatusOK, response)
+		return
+	}
 
-// bToMb 字节转MB
-func bToMb(b uint64) uint64 {
-	return b / 1024 / 1024
+	respondWithSuccess(c, http.StatusServiceUnavailable, response)
 }
 
 // CleanExpiredLinks 清理过期链接（管理员接口）
 func (h *Handler) CleanExpiredLinks(c *gin.Context) {
 	deletedCount, err := h.shortLinkService.CleanExpiredLinks(c.Request.Context())
 	if err != nil {
-		h.logger.Error("failed to clean expired links", zap.Error(err))
+		h.loggerFor(c).Error("failed to clean expired links", zap.Error(err))
 		respondWithError(c, http.StatusInternalServerError, "failed to clean expired links")
 		return
 	}
@@ -193,3 +410,46 @@ func (h *Handler) CleanExpiredLinks(c *gin.Context) {
 
 	respondWithSuccess(c, http.StatusOK, result, "expired links cleaned successfully")
 }
+
+// RebuildBloomFilter 后台重建布隆过滤器（管理员接口）
+func (h *Handler) RebuildBloomFilter(c *gin.Context) {
+	status, err := h.shortLinkService.StartBloomFilterRebuild()
+	if err != nil {
+		if errors.Is(err, service.ErrBloomSyncRunning) {
+			respondWithError(c, http.StatusConflict, "bloom filter rebuild already running")
+			return
+		}
+		h.loggerFor(c).Error("failed to start bloom filter rebuild", zap.Error(err))
+		respondWithError(c, http.StatusInternalServerError, "failed to start bloom filter rebuild")
+		return
+	}
+
+	respondWithSuccess(c, http.StatusAccepted, status, "bloom filter rebuild started")
+}
+
+// GetBloomFilterRebuildStatus 获取布隆过滤器重建进度（管理员接口）
+func (h *Handler) GetBloomFilterRebuildStatus(c *gin.Context) {
+	respondWithSuccess(c, http.StatusOK, h.shortLinkService.BloomFilterRebuildStatus())
+}
+
+// GetBloomFilterStats 获取布隆过滤器容量、填充率和估算误判率（管理员接口）
+func (h *Handler) GetBloomFilterStats(c *gin.Context) {
+	stats, err := h.shortLinkService.BloomFilterStats(c.Request.Context())
+	if err != nil {
+		h.loggerFor(c).Error("failed to get bloom filter stats", zap.Error(err))
+		respondWithError(c, http.StatusInternalServerError, "failed to get bloom filter stats")
+		return
+	}
+
+	result := map[string]interface{}{
+		"filter":  stats,
+		"rebuild": h.shortLinkService.BloomFilterRebuildStatus(),
+	}
+
+	respondWithSuccess(c, http.StatusOK, result)
+}
+
+// GetRedirectStats 获取重定向缓存命中、未命中和合并计数（管理员接口）
+func (h *Handler) GetRedirectStats(c *gin.Context) {
+	respondWithSuccess(c, http.StatusOK, h.shortLinkService.RedirectStats())
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"short-url/internal/config"
+	"short-url/internal/ratelimit"
+	"short-url/pkg/logger"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 限流策略默认值
+const (
+	defaultRateLimitWindow  = time.Minute
+	defaultAPIRequests      = 100
+	defaultCreateRequests   = 20
+	defaultRedirectRequests = 600
+	defaultAdminRequests    = 30
+	defaultAuthRequests     = 300
+)
+
+// RateLimitSubjectKey 认证中间件在 gin.Context 中写入的调用方标识（如 API key ID），
+// 存在时按该标识限流，否则按客户端 IP 限流
+const RateLimitSubjectKey = "rate_limit_subject"
+
+// rateLimitPolicies 各路由组的限流策略，所有组使用相同的窗口
+type rateLimitPolicies struct {
+	// API 创建和管理员接口以外的 /api/v1 接口
+	API      ratelimit.Policy
+	Create   ratelimit.Policy
+	Redirect ratelimit.Policy
+	Admin    ratelimit.Policy
+	// Auth 校验 API key 之前按客户端 IP 计数，限制猜测 key 的速度
+	Auth ratelimit.Policy
+}
+
+func newRateLimitPolicies(cfg *config.RateLimitConfig) rateLimitPolicies {
+	window := cfg.Window
+	if window <= 0 {
+		window = defaultRateLimitWindow
+	}
+	policy := func(name string, requests, defaultRequests int) ratelimit.Policy {
+		if requests <= 0 {
+			requests = defaultRequests
+		}
+		return ratelimit.Policy{Name: name, Limit: int64(requests), Window: window}
+	}
+
+	return rateLimitPolicies{
+		API:      policy("api", cfg.Requests, defaultAPIRequests),
+		Create:   policy("create", cfg.CreateRequests, defaultCreateRequests),
+		Redirect: policy("redirect", cfg.RedirectRequests, defaultRedirectRequests),
+		Admin:    policy("admin", cfg.AdminRequests, defaultAdminRequests),
+		Auth:     policy("auth", cfg.AuthRequests, defaultAuthRequests),
+	}
+}
+
+// RateLimitMiddleware 限流中间件，按 API key 或客户端 IP 计数，响应 RateLimit-* 头，超限返回 429 和 Retry-After。
+// limiter 为 nil 时不限流；限流器出错时放行请求
+func RateLimitMiddleware(limiter ratelimit.Limiter, policy ratelimit.Policy, baseLogger *zap.Logger) gin.HandlerFunc {
+	return rateLimitMiddleware(limiter, policy, rateLimitSubject, baseLogger)
+}
+
+// AuthRateLimitMiddleware 在认证之前按客户端 IP 限制携带 Authorization 头的请求，
+// 无效 key 也会被计数，避免无限制地猜测 key 和查询数据库。匿名请求由各路由组按 IP 限流
+func AuthRateLimitMiddleware(limiter ratelimit.Limiter, policy ratelimit.Policy, baseLogger *zap.Logger) gin.HandlerFunc {
+	limit := rateLimitMiddleware(limiter, policy, clientIPSubject, baseLogger)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		limit(c)
+	}
+}
+
+func rateLimitMiddleware(limiter ratelimit.Limiter, policy ratelimit.Policy, subject func(*gin.Context) string, baseLogger *zap.Logger) gin.HandlerFunc {
+	if limiter == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit, int64(policy.Window.Seconds()))
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), policy, subject(c))
+		if err != nil {
+			logger.FromContext(c.Request.Context(), baseLogger).Warn("rate limit check failed", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policyHeader)
+		c.Header("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		c.Header("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.Reset), 10))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.FormatInt(max(ceilSeconds(result.RetryAfter), 1), 10))
+			respondWithError(c, http.StatusTooManyRequests, "rate limit exceeded")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitSubject 返回限流主体，已认证的调用方按标识计数，其余按客户端 IP
+func rateLimitSubject(c *gin.Context) string {
+	if subject := c.GetString(RateLimitSubjectKey); subject != "" {
+		return "key:" + subject
+	}
+	return clientIPSubject(c)
+}
+
+// clientIPSubject 按客户端 IP 计数的限流主体
+func clientIPSubject(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"short-url/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader 请求 ID 请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength 接受的客户端请求 ID 最大长度，超出或含非法字符时重新生成
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware 请求 ID 中间件：沿用合法的 X-Request-ID 或生成新的，写入响应头，
+// 并把请求 ID 和带 request_id 字段的日志器放入请求 context，供 Handler 和 ShortLinkService 使用
+func RequestIDMiddleware(baseLogger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		fields := []zap.Field{zap.String("request_id", requestID)}
+		ctx := c.Request.Context()
+		// 启用链路追踪时同时记录 trace_id，便于从日志跳转到调用链
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			fields = append(fields, zap.String("trace_id", spanContext.TraceID().String()))
+		}
+
+		ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+		ctx = logger.NewContext(ctx, baseLogger.With(fields...))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// requestIDFromContext 返回请求 ID，不经过 RequestIDMiddleware 的请求返回空字符串
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// loggerFor 返回请求级日志器
+func (h *Handler) loggerFor(c *gin.Context) *zap.Logger {
+	return logger.FromContext(c.Request.Context(), h.logger)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID 只接受由字母、数字和 -_.: 组成的请求 ID，避免日志注入
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
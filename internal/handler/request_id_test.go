@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"short-url/pkg/logger"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantEcho bool
+	}{
+		{name: "generated when missing", wantEcho: false},
+		{name: "client id kept", header: "req-123_abc.def:1", wantEcho: true},
+		{name: "invalid characters replaced", header: "bad id\nforged=1", wantEcho: false},
+		{name: "too long replaced", header: strings.Repeat("a", maxRequestIDLength+1), wantEcho: false},
+		{name: "max length kept", header: strings.Repeat("a", maxRequestIDLength), wantEcho: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			router := gin.New()
+			router.Use(RequestIDMiddleware(zap.New(core)))
+			router.GET("/fail", func(c *gin.Context) {
+				logger.FromContext(c.Request.Context(), zap.NewNop()).Info("handled")
+				respondWithError(c, http.StatusBadRequest, "bad request")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			if tt.wantEcho && requestID != tt.header {
+				t.Errorf("%s = %q, want %q", RequestIDHeader, requestID, tt.header)
+			}
+			if !tt.wantEcho && (requestID == tt.header || !validRequestID(requestID)) {
+				t.Errorf("%s = %q, want a newly generated id", RequestIDHeader, requestID)
+			}
+
+			var body ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if body.RequestID != requestID {
+				t.Errorf("response request_id = %q, want %q", body.RequestID, requestID)
+			}
+
+			entries := logs.FilterField(zap.String("request_id", requestID)).All()
+			if len(entries) != 1 {
+				t.Errorf("log entries with request_id %q = %d, want 1", requestID, len(entries))
+			}
+		})
+	}
+}
@@ -2,15 +2,17 @@ package handler
 
 import (
 	"net/http"
+	"short-url/internal/health"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ErrorResponse 错误响应结构
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Code    int    `json:"code"`
+	Error     string `json:"error"`
+	Message   string `json:"message,omitempty"`
+	Code      int    `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // SuccessResponse 成功响应结构
@@ -22,9 +24,10 @@ type SuccessResponse struct {
 // respondWithError 返回错误响应
 func respondWithError(c *gin.Context, code int, message string) {
 	c.JSON(code, ErrorResponse{
-		Error:   http.StatusText(code),
-		Message: message,
-		Code:    code,
+		Error:     http.StatusText(code),
+		Message:   message,
+		Code:      code,
+		RequestID: requestIDFromContext(c.Request.Context()),
 	})
 }
 
@@ -39,7 +42,7 @@ func respondWithSuccess(c *gin.Context, code int, data interface{}, message ...s
 
 // Health 健康检查响应
 type Health struct {
-	Status    string                 `json:"status"`
-	Timestamp string                 `json:"timestamp"`
-	Services  map[string]interface{} `json:"services,omitempty"`
+	Status    string                        `json:"status"`
+	Timestamp string                        `json:"timestamp"`
+	Services  map[string]health.CheckResult `json:"services,omitempty"`
 }
@@ -1,52 +1,140 @@
 package handler
 
 import (
+	"short-url/internal/config"
+	"short-url/internal/metrics"
+	"short-url/internal/models"
+	"short-url/internal/ratelimit"
+	"short-url/internal/tracing"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // SetupRoutes 设置路由
-func SetupRoutes(handler *Handler, logger *zap.Logger) *gin.Engine {
+func SetupRoutes(handler *Handler, metrics *metrics.Metrics, limiter ratelimit.Limiter, cfg *config.Config, logger *zap.Logger) *gin.Engine {
 	// 根据环境设置Gin模式
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.New()
 
+	// 只信任来自可信代理的 X-Forwarded-For，避免伪造客户端 IP 绕过限流
+	if len(cfg.App.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+			logger.Error("invalid trusted proxies, trusting all proxies", zap.Error(err))
+		}
+	}
+
 	// 中间件
 	r.Use(gin.Recovery())
+	r.Use(tracing.Middleware(cfg.Tracing.ServiceName))
+	r.Use(RequestIDMiddleware(logger))
+	r.Use(metrics.Middleware())
 	r.Use(LoggerMiddleware(logger))
 	r.Use(CORSMiddleware())
 
 	// 健康检查
-	r.GET("/health", handler.Health)
+	r.GET("/health", handler.Liveness)
+	r.GET("/health/live", handler.Liveness)
+	r.GET("/health/ready", handler.Readiness)
+
+	// 各路由组分别限流
+	policies := newRateLimitPolicies(&cfg.RateLimit)
 
-	// 调试接口
-	r.GET("/debug/memory", handler.MemoryStats)
+	// Prometheus 指标和调试接口，配置了内部端口时只在内部端口提供；
+	// 挂在主端口时与管理员接口一样，要求 admin 权限的 API key 或 ADMIN_TOKEN
+	if cfg.Debug.Addr == "" {
+		adminAuth := []gin.HandlerFunc{AuthRateLimitMiddleware(limiter, policies.Auth, logger), handler.Authenticate, RequireScope(models.ScopeAdmin)}
+		r.GET("/metrics", append(adminAuth, gin.WrapH(metrics.Handler()))...)
+		setupDebugRoutes(r, handler, adminAuth...)
+	}
+
+	// 创建和查询接口只在 AUTH_REQUIRE_API_KEY 开启时要求对应权限；
+	// 修改、删除、停用、启用会影响他人创建的短链接，总是要求 create 权限，管理员接口总是要求 admin 权限
+	create, read := optionalScope(cfg.Auth.RequireAPIKey, models.ScopeCreate), optionalScope(cfg.Auth.RequireAPIKey, models.ScopeRead)
+	write := RequireScope(models.ScopeCreate)
 
-	// API v1 路由组
-	v1 := r.Group("/api/v1")
+	// API v1 路由组，校验 API key 之前先按客户端 IP 限流，认证后各路由组按 API key 计数
+	v1 := r.Group("/api/v1", AuthRateLimitMiddleware(limiter, policies.Auth, logger), handler.Authenticate)
 	{
-		v1.POST("/shorten", handler.CreateShortLink)
-		v1.GET("/info/:code", handler.GetShortLinkInfo)
-		v1.GET("/stats", handler.GetStats)
+		// 创建接口
+		shorten := v1.Group("", RateLimitMiddleware(limiter, policies.Create, logger), create)
+		{
+			shorten.POST("/shorten", handler.CreateShortLink)
+			shorten.POST("/shorten/batch", handler.BatchCreateShortLinks)
+		}
+
+		api := v1.Group("", RateLimitMiddleware(limiter, policies.API, logger))
+		{
+			api.GET("/info/:code", read, handler.GetShortLinkInfo)
+			api.GET("/lookup", read, handler.LookupShortLinks)
+			api.GET("/links", read, handler.ListShortLinks)
+			api.PATCH("/links/:code", write, handler.UpdateShortLink)
+			api.DELETE("/links/:code", write, handler.DeleteShortLink)
+			api.POST("/links/:code/disable", write, handler.DisableShortLink)
+			api.POST("/links/:code/enable", write, handler.EnableShortLink)
+			api.GET("/links/:code/analytics", read, handler.GetLinkAnalytics)
+			api.GET("/stats", read, handler.GetStats)
+		}
 
 		// 管理员接口
-		admin := v1.Group("/admin")
+		admin := v1.Group("/admin", RateLimitMiddleware(limiter, policies.Admin, logger), RequireScope(models.ScopeAdmin))
 		{
 			admin.POST("/clean", handler.CleanExpiredLinks)
+			admin.GET("/cache", handler.GetRedirectStats)
+			admin.GET("/bloom", handler.GetBloomFilterStats)
+			admin.POST("/bloom/rebuild", handler.RebuildBloomFilter)
+			admin.GET("/bloom/rebuild", handler.GetBloomFilterRebuildStatus)
 		}
 	}
 
 	// 短链接重定向（放在最后，避免与API路由冲突）
-	r.GET("/:code", handler.RedirectToOriginal)
+	r.GET("/:code", RateLimitMiddleware(limiter, policies.Redirect, logger), handler.RedirectToOriginal)
 
 	return r
 }
 
+// optionalScope required 为 true 时要求 scope 权限，否则不做检查
+func optionalScope(required bool, scope string) gin.HandlerFunc {
+	if required {
+		return RequireScope(scope)
+	}
+	return func(c *gin.Context) {
+		c.Next()
+	}
+}
+
+// SetupDebugRoutes 设置内部端口的路由，提供 Prometheus 指标和调试接口。
+// 内部端口只应在内网可达，指标无需认证，调试接口仍需 admin 权限
+func SetupDebugRoutes(handler *Handler, metrics *metrics.Metrics, cfg *config.Config, logger *zap.Logger) *gin.Engine {
+	r := gin.New()
+
+	r.Use(gin.Recovery())
+	r.Use(RequestIDMiddleware(logger))
+	r.Use(LoggerMiddleware(logger))
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	setupDebugRoutes(r, handler, handler.Authenticate, RequireScope(models.ScopeAdmin))
+
+	return r
+}
+
+// setupDebugRoutes 注册运行时指标和 pprof 接口，auth 为校验 admin 权限的中间件
+func setupDebugRoutes(r gin.IRouter, handler *Handler, auth ...gin.HandlerFunc) {
+	debug := r.Group("/debug", auth...)
+	{
+		debug.GET("/runtime", handler.RuntimeMetrics)
+		debug.GET("/pprof/*name", handler.Pprof)
+		debug.POST("/pprof/*name", handler.Pprof)
+	}
+}
+
 // LoggerMiddleware 日志中间件
 func LoggerMiddleware(logger *zap.Logger) gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		logger.Info("HTTP Request",
+			zap.String("request_id", requestIDFromContext(param.Request.Context())),
 			zap.String("client_ip", param.ClientIP),
 			zap.String("method", param.Method),
 			zap.String("path", param.Path),
@@ -63,8 +151,9 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
+		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -74,12 +163,3 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-// RateLimitMiddleware 限流中间件（可选实现）
-func RateLimitMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 这里可以实现基于IP或用户的限流逻辑
-		// 例如使用Redis存储访问计数和时间窗口
-		c.Next()
-	}
-}
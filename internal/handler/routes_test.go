@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/cache"
+	"short-url/internal/config"
+	"short-url/internal/health"
+	"short-url/internal/metrics"
+	"short-url/internal/models"
+	"short-url/internal/service"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestAdminOnlyRoutes(t *testing.T) {
+	const adminToken = "s3cret"
+
+	tests := []struct {
+		name       string
+		debugPort  bool
+		adminToken string
+		path       string
+		// credential 为 "token"（ADMIN_TOKEN）、"admin"、"read"（对应权限的 API key）或空
+		credential string
+		want       int
+	}{
+		{name: "metrics without credentials", adminToken: adminToken, path: "/metrics", want: http.StatusUnauthorized},
+		{name: "metrics with admin token", adminToken: adminToken, path: "/metrics", credential: "token", want: http.StatusOK},
+		{name: "metrics with admin key", adminToken: adminToken, path: "/metrics", credential: "admin", want: http.StatusOK},
+		{name: "metrics with read key", adminToken: adminToken, path: "/metrics", credential: "read", want: http.StatusForbidden},
+		{name: "metrics with admin key and no admin token", path: "/metrics", credential: "admin", want: http.StatusOK},
+		{name: "debug without credentials", adminToken: adminToken, path: "/debug/runtime", want: http.StatusUnauthorized},
+		{name: "debug with admin token", adminToken: adminToken, path: "/debug/runtime", credential: "token", want: http.StatusOK},
+		{name: "debug with admin key", adminToken: adminToken, path: "/debug/runtime", credential: "admin", want: http.StatusOK},
+		{name: "debug with read key", adminToken: adminToken, path: "/debug/runtime", credential: "read", want: http.StatusForbidden},
+		{name: "debug port metrics without credentials", debugPort: true, adminToken: adminToken, path: "/metrics", want: http.StatusOK},
+		{name: "debug port debug without credentials", debugPort: true, adminToken: adminToken, path: "/debug/runtime", want: http.StatusUnauthorized},
+		{name: "debug port debug with admin key", debugPort: true, adminToken: adminToken, path: "/debug/runtime", credential: "admin", want: http.StatusOK},
+		{name: "debug port debug with read key", debugPort: true, adminToken: adminToken, path: "/debug/runtime", credential: "read", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := &config.Config{
+				App:   config.AppConfig{BaseURL: "http://localhost:8080", AdminToken: tt.adminToken},
+				Cache: config.CacheConfig{TTL: time.Hour},
+				BloomFilter: config.BloomFilterConfig{
+					Backend: cache.BloomBackendMemory, Key: "test", Capacity: 1000, ErrorRate: 0.01,
+				},
+			}
+
+			repo := service.NewMemoryRepository()
+			shortLinkService := service.NewShortLinkService(repo, cache.NewLRUCache(&cfg.Cache),
+				cache.NewBloomFilter(nil, &cfg.BloomFilter), service.NewMemoryAccessCounter(), nil, cfg, zap.NewNop())
+			apiKeys := service.NewAPIKeyService(repo, tt.adminToken, 0)
+
+			credentials := map[string]string{"token": adminToken}
+			for _, scope := range []string{models.ScopeAdmin, models.ScopeRead} {
+				_, rawKey, err := apiKeys.IssueAPIKey(ctx, scope+"-key", []string{scope})
+				if err != nil {
+					t.Fatalf("IssueAPIKey() error = %v", err)
+				}
+				credentials[scope] = rawKey
+			}
+
+			h := NewHandler(shortLinkService, apiKeys, nil, zap.NewNop())
+			var router *gin.Engine
+			if tt.debugPort {
+				router = SetupDebugRoutes(h, metrics.New(), cfg, zap.NewNop())
+			} else {
+				router = SetupRoutes(h, metrics.New(), nil, cfg, zap.NewNop())
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.credential != "" {
+				req.Header.Set("Authorization", "Bearer "+credentials[tt.credential])
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d: %s", tt.path, w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHealthRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		dependency error
+		draining   bool
+		want       int
+		wantStatus string
+	}{
+		{name: "liveness ignores dependencies", path: "/health/live", dependency: errors.New("down"), want: http.StatusOK, wantStatus: "ok"},
+		{name: "liveness while draining", path: "/health/live", draining: true, want: http.StatusOK, wantStatus: "ok"},
+		{name: "ready", path: "/health/ready", want: http.StatusOK, wantStatus: "ok"},
+		{name: "dependency down", path: "/health/ready", dependency: errors.New("down"), want: http.StatusServiceUnavailable, wantStatus: "unavailable"},
+		{name: "draining", path: "/health/ready", draining: true, want: http.StatusServiceUnavailable, wantStatus: "draining"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				App:   config.AppConfig{BaseURL: "http://localhost:8080"},
+				Cache: config.CacheConfig{TTL: time.Hour},
+				BloomFilter: config.BloomFilterConfig{
+					Backend: cache.BloomBackendMemory, Key: "test", Capacity: 1000, ErrorRate: 0.01,
+				},
+			}
+			repo := service.NewMemoryRepository()
+			shortLinkService := service.NewShortLinkService(repo, cache.NewLRUCache(&cfg.Cache),
+				cache.NewBloomFilter(nil, &cfg.BloomFilter), service.NewMemoryAccessCounter(), nil, cfg, zap.NewNop())
+
+			checker := health.NewChecker(time.Second)
+			checker.Register("postgres", func(ctx context.Context) error { return tt.dependency })
+			if tt.draining {
+				checker.SetDraining()
+			}
+
+			h := NewHandler(shortLinkService, service.NewAPIKeyService(repo, "", 0), checker, zap.NewNop())
+			router := SetupRoutes(h, metrics.New(), nil, cfg, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+			}
+			var body struct {
+				Data Health `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if body.Data.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", body.Data.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 依赖检查状态
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// CheckFunc 依赖检查函数，返回 nil 表示依赖可用
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult 单个依赖的检查结果
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker 汇总各依赖的健康检查，并记录服务是否正在停止
+type Checker struct {
+	timeout  time.Duration
+	checks   []check
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register 注册依赖检查，需在开始处理请求前完成
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// SetDraining 标记服务正在停止，此后就绪检查失败
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Draining 服务是否正在停止
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Check 并发执行所有依赖检查，每项检查单独超时，返回各项结果以及是否全部可用
+func (c *Checker) Check(ctx context.Context) (map[string]CheckResult, bool) {
+	results := make(map[string]CheckResult, len(c.checks))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+
+			result := c.run(ctx, chk.fn)
+
+			mu.Lock()
+			results[chk.name] = result
+			mu.Unlock()
+		}(chk)
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, result := range results {
+		if result.Status != StatusUp {
+			healthy = false
+		}
+	}
+
+	return results, healthy
+}
+
+func (c *Checker) run(ctx context.Context, fn CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
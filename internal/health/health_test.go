@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckerCheck(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	// hang 一直等到检查超时
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name        string
+		checks      map[string]CheckFunc
+		wantHealthy bool
+		wantStatus  map[string]string
+	}{
+		{name: "no checks", wantHealthy: true, wantStatus: map[string]string{}},
+		{
+			name:        "all up",
+			checks:      map[string]CheckFunc{"postgres": up, "redis": up},
+			wantHealthy: true,
+			wantStatus:  map[string]string{"postgres": StatusUp, "redis": StatusUp},
+		},
+		{
+			name:        "one down",
+			checks:      map[string]CheckFunc{"postgres": up, "redis": down},
+			wantHealthy: false,
+			wantStatus:  map[string]string{"postgres": StatusUp, "redis": StatusDown},
+		},
+		{
+			name:        "hung check times out",
+			checks:      map[string]CheckFunc{"postgres": hang, "redis": up},
+			wantHealthy: false,
+			wantStatus:  map[string]string{"postgres": StatusDown, "redis": StatusUp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(50 * time.Millisecond)
+			for name, fn := range tt.checks {
+				checker.Register(name, fn)
+			}
+
+			results, healthy := checker.Check(context.Background())
+			if healthy != tt.wantHealthy {
+				t.Errorf("Check() healthy = %v, want %v", healthy, tt.wantHealthy)
+			}
+			if len(results) != len(tt.wantStatus) {
+				t.Errorf("Check() returned %d results, want %d", len(results), len(tt.wantStatus))
+			}
+			for name, want := range tt.wantStatus {
+				result := results[name]
+				if result.Status != want {
+					t.Errorf("%s status = %q, want %q", name, result.Status, want)
+				}
+				if (result.Error != "") != (want == StatusDown) {
+					t.Errorf("%s error = %q with status %q", name, result.Error, result.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"short-url/internal/service"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// constMetric 采集时由快照生成的指标
+type constMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func() float64
+}
+
+// snapshotCollector 每次采集时读取一次快照并生成常量指标，避免在业务路径上重复计数
+type snapshotCollector struct {
+	// mu 保证并发采集时快照不被覆盖
+	mu      sync.Mutex
+	prepare func()
+	metrics []constMetric
+}
+
+func (c *snapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric.desc
+	}
+}
+
+func (c *snapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prepare()
+	for _, metric := range c.metrics {
+		ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, metric.value())
+	}
+}
+
+func newDesc(subsystem, name, help string, constLabels prometheus.Labels) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, nil, constLabels)
+}
+
+// NewServiceCollector 采集重定向缓存、布隆过滤器、点击事件和短码生成计数
+func NewServiceCollector(svc *service.ShortLinkService) prometheus.Collector {
+	var (
+		redirect   service.RedirectStatsSnapshot
+		generation service.GenerationStatsSnapshot
+	)
+
+	counter := func(subsystem, name, help string, labels prometheus.Labels, value func() int64) constMetric {
+		return constMetric{
+			desc:      newDesc(subsystem, name, help, labels),
+			valueType: prometheus.CounterValue,
+			value:     func() float64 { return float64(value()) },
+		}
+	}
+
+	return &snapshotCollector{
+		prepare: func() {
+			redirect = svc.RedirectStats()
+			generation = svc.GenerationStats()
+		},
+		metrics: []constMetric{
+			counter("redirect", "cache_lookups_total", "Redirect cache lookups by result.",
+				prometheus.Labels{"result": "hit"}, func() int64 { return redirect.CacheHits }),
+			counter("redirect", "cache_lookups_total", "Redirect cache lookups by result.",
+				prometheus.Labels{"result": "negative_hit"}, func() int64 { return redirect.NegativeHits }),
+			counter("redirect", "cache_lookups_total", "Redirect cache lookups by result.",
+				prometheus.Labels{"result": "miss"}, func() int64 { return redirect.CacheMisses }),
+			{
+				desc:      newDesc("redirect", "cache_hit_ratio", "Share of redirect cache lookups answered from cache, including negative hits.", nil),
+				valueType: prometheus.GaugeValue,
+				value:     func() float64 { return redirect.HitRatio },
+			},
+			counter("redirect", "db_lookups_total", "Database lookups after a redirect cache miss.",
+				nil, func() int64 { return redirect.DBLookups }),
+			counter("redirect", "collapsed_total", "Redirect cache misses that shared an in-flight database lookup.",
+				nil, func() int64 { return redirect.Collapsed }),
+			counter("bloom", "checks_total", "Bloom filter checks on the redirect path by result.",
+				prometheus.Labels{"result": "reject"}, func() int64 { return redirect.BloomRejects }),
+			counter("bloom", "checks_total", "Bloom filter checks on the redirect path by result.",
+				prometheus.Labels{"result": "pass"}, func() int64 { return redirect.BloomPasses }),
+			counter("bloom", "false_positives_total", "Bloom filter positives that the database did not confirm.",
+				prometheus.Labels{"path": "redirect"}, func() int64 { return redirect.BloomFalsePositives }),
+			counter("bloom", "false_positives_total", "Bloom filter positives that the database did not confirm.",
+				prometheus.Labels{"path": "generate"}, func() int64 { return generation.BloomFalsePositives }),
+			counter("clicks", "recorded_total", "Click events written to storage.",
+				nil, func() int64 { return redirect.ClicksRecorded }),
+			counter("clicks", "dropped_total", "Click events dropped because the buffer was full or the write failed.",
+				nil, func() int64 { return redirect.ClicksDropped }),
+			counter("short_code", "retries_total", "Generated short codes discarded because they were already taken.",
+				nil, func() int64 { return generation.Retries }),
+			counter("short_code", "generation_failures_total", "Short code generations that ran out of retries.",
+				nil, func() int64 { return generation.Failures }),
+		},
+	}
+}
+
+// NewDBPoolCollector 采集 pgxpool 连接池统计
+func NewDBPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	var stat *pgxpool.Stat
+
+	gauge := func(name, help string, value func() int32) constMetric {
+		return constMetric{
+			desc:      newDesc("db_pool", name, help, nil),
+			valueType: prometheus.GaugeValue,
+			value:     func() float64 { return float64(value()) },
+		}
+	}
+	counter := func(name, help string, value func() int64) constMetric {
+		return constMetric{
+			desc:      newDesc("db_pool", name, help, nil),
+			valueType: prometheus.CounterValue,
+			value:     func() float64 { return float64(value()) },
+		}
+	}
+
+	return &snapshotCollector{
+		prepare: func() { stat = pool.Stat() },
+		metrics: []constMetric{
+			gauge("acquired_conns", "Connections currently checked out of the pool.", func() int32 { return stat.AcquiredConns() }),
+			gauge("idle_conns", "Idle connections in the pool.", func() int32 { return stat.IdleConns() }),
+			gauge("constructing_conns", "Connections being established.", func() int32 { return stat.ConstructingConns() }),
+			gauge("total_conns", "Total connections in the pool.", func() int32 { return stat.TotalConns() }),
+			gauge("max_conns", "Maximum size of the pool.", func() int32 { return stat.MaxConns() }),
+			counter("acquires_total", "Successful connection acquires.", func() int64 { return stat.AcquireCount() }),
+			counter("empty_acquires_total", "Acquires that had to wait because the pool had no idle connection.", func() int64 { return stat.EmptyAcquireCount() }),
+			counter("canceled_acquires_total", "Acquires canceled by their context.", func() int64 { return stat.CanceledAcquireCount() }),
+			counter("new_conns_total", "Connections opened.", func() int64 { return stat.NewConnsCount() }),
+			{
+				desc:      newDesc("db_pool", "acquire_duration_seconds_total", "Total time spent acquiring connections.", nil),
+				valueType: prometheus.CounterValue,
+				value:     func() float64 { return stat.AcquireDuration().Seconds() },
+			},
+		},
+	}
+}
+
+// NewRedisPoolCollector 采集 go-redis 连接池统计
+func NewRedisPoolCollector(client *redis.Client) prometheus.Collector {
+	var stats *redis.PoolStats
+
+	metric := func(name, help string, valueType prometheus.ValueType, value func() uint32) constMetric {
+		return constMetric{
+			desc:      newDesc("redis_pool", name, help, nil),
+			valueType: valueType,
+			value:     func() float64 { return float64(value()) },
+		}
+	}
+
+	return &snapshotCollector{
+		prepare: func() { stats = client.PoolStats() },
+		metrics: []constMetric{
+			metric("hits_total", "Times a free connection was found in the pool.", prometheus.CounterValue, func() uint32 { return stats.Hits }),
+			metric("misses_total", "Times a free connection was not found in the pool.", prometheus.CounterValue, func() uint32 { return stats.Misses }),
+			metric("timeouts_total", "Times a wait for a connection timed out.", prometheus.CounterValue, func() uint32 { return stats.Timeouts }),
+			metric("stale_conns_total", "Stale connections removed from the pool.", prometheus.CounterValue, func() uint32 { return stats.StaleConns }),
+			metric("total_conns", "Total connections in the pool.", prometheus.GaugeValue, func() uint32 { return stats.TotalConns }),
+			metric("idle_conns", "Idle connections in the pool.", prometheus.GaugeValue, func() uint32 { return stats.IdleConns }),
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/cache"
+	"short-url/internal/config"
+	"short-url/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+func TestMiddlewareLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantMethod string
+		wantRoute  string
+		wantStatus string
+	}{
+		{name: "route template", method: http.MethodGet, path: "/abc123", wantMethod: "GET", wantRoute: "/:code", wantStatus: "302"},
+		{name: "unmatched path", method: http.MethodGet, path: "/a/b/c", wantMethod: "GET", wantRoute: unmatchedRoute, wantStatus: "404"},
+		{name: "unknown method", method: "PURGE", path: "/abc123", wantMethod: otherMethod, wantRoute: unmatchedRoute, wantStatus: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			router := gin.New()
+			router.Use(m.Middleware())
+			router.GET("/:code", func(c *gin.Context) { c.Redirect(http.StatusFound, "https://example.com") })
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got := testutil.ToFloat64(m.requests.WithLabelValues(tt.wantMethod, tt.wantRoute, tt.wantStatus)); got != 1 {
+				t.Errorf("requests{method=%q, route=%q, status=%q} = %v, want 1", tt.wantMethod, tt.wantRoute, tt.wantStatus, got)
+			}
+			if got := testutil.CollectAndCount(m.requests); got != 1 {
+				t.Errorf("requests series = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestServiceCollector(t *testing.T) {
+	cfg := &config.Config{
+		App:   config.AppConfig{BaseURL: "http://localhost:8080"},
+		Cache: config.CacheConfig{TTL: time.Hour},
+		BloomFilter: config.BloomFilterConfig{
+			Backend: cache.BloomBackendMemory, Key: "test", Capacity: 1000, ErrorRate: 0.01,
+		},
+	}
+	bloomFilter := cache.NewBloomFilter(nil, &cfg.BloomFilter)
+	if err := bloomFilter.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	svc := service.NewShortLinkService(service.NewMemoryRepository(), cache.NewLRUCache(&cfg.Cache),
+		bloomFilter, service.NewMemoryAccessCounter(), nil, cfg, zap.NewNop())
+
+	// 第一次未命中查询数据库并写入负缓存，第二次命中负缓存
+	for i := 0; i < 2; i++ {
+		if _, err := svc.GetOriginalURL(context.Background(), "code01"); !errors.Is(err, service.ErrShortCodeNotFound) {
+			t.Fatalf("GetOriginalURL() error = %v, want %v", err, service.ErrShortCodeNotFound)
+		}
+	}
+
+	want := `
+# HELP shorturl_redirect_cache_lookups_total Redirect cache lookups by result.
+# TYPE shorturl_redirect_cache_lookups_total counter
+shorturl_redirect_cache_lookups_total{result="hit"} 0
+shorturl_redirect_cache_lookups_total{result="miss"} 1
+shorturl_redirect_cache_lookups_total{result="negative_hit"} 1
+# HELP shorturl_redirect_db_lookups_total Database lookups after a redirect cache miss.
+# TYPE shorturl_redirect_db_lookups_total counter
+shorturl_redirect_db_lookups_total 1
+`
+	err := testutil.CollectAndCompare(NewServiceCollector(svc), strings.NewReader(want),
+		"shorturl_redirect_cache_lookups_total", "shorturl_redirect_db_lookups_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
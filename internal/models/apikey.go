@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// API key 权限范围，admin 包含其他全部权限
+const (
+	ScopeCreate = "create"
+	ScopeRead   = "read"
+	ScopeAdmin  = "admin"
+)
+
+// APIKey API key 元数据，不包含明文和哈希
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope 检查是否具有指定权限，admin 权限包含其他全部权限
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestAPIKeyHasScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{name: "granted", scopes: []string{ScopeRead}, scope: ScopeRead, want: true},
+		{name: "not granted", scopes: []string{ScopeRead}, scope: ScopeCreate, want: false},
+		{name: "one of several", scopes: []string{ScopeCreate, ScopeRead}, scope: ScopeRead, want: true},
+		{name: "admin implies create", scopes: []string{ScopeAdmin}, scope: ScopeCreate, want: true},
+		{name: "admin implies read", scopes: []string{ScopeAdmin}, scope: ScopeRead, want: true},
+		{name: "create does not imply admin", scopes: []string{ScopeCreate, ScopeRead}, scope: ScopeAdmin, want: false},
+		{name: "no scopes", scopes: nil, scope: ScopeRead, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &APIKey{Scopes: tt.scopes}
+			if got := key.HasScope(tt.scope); got != tt.want {
+				t.Errorf("HasScope(%q) with %v = %v, want %v", tt.scope, tt.scopes, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,15 +4,25 @@ import (
 	"time"
 )
 
+// 短链接状态
+const (
+	LinkStatusActive   = "active"
+	LinkStatusDisabled = "disabled"
+	LinkStatusDeleted  = "deleted"
+)
+
 // ShortLink 短链接数据模型
 type ShortLink struct {
-	ID          int64      `json:"id" db:"id"`
-	ShortCode   string     `json:"short_code" db:"short_code"`
-	OriginalURL string     `json:"original_url" db:"original_url"`
-	AccessCount int64      `json:"access_count" db:"access_count"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	ID             int64      `json:"id" db:"id"`
+	ShortCode      string     `json:"short_code" db:"short_code"`
+	OriginalURL    string     `json:"original_url" db:"original_url"`
+	AccessCount    int64      `json:"access_count" db:"access_count"`
+	UniqueVisitors int64      `json:"unique_visitors" db:"unique_visitors"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	Status         string     `json:"status" db:"status"`
+	StatusReason   string     `json:"status_reason,omitempty" db:"status_reason"`
 }
 
 // CreateShortLinkRequest 创建短链接请求
@@ -20,6 +30,38 @@ type CreateShortLinkRequest struct {
 	URL        string     `json:"url" binding:"required,url"`
 	CustomCode string     `json:"custom_code,omitempty"`
 	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	// ReuseExisting 为 true 且未指定自定义短码时，若相同URL已有未过期的短链接则直接返回
+	ReuseExisting bool `json:"reuse_existing,omitempty"`
+}
+
+// BatchCreateShortLinkRequest 批量创建短链接请求
+type BatchCreateShortLinkRequest struct {
+	Items []CreateShortLinkRequest `json:"items" binding:"required"`
+}
+
+// ListShortLinksRequest 短链接列表查询参数
+type ListShortLinksRequest struct {
+	CreatedAfter   *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore  *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Expiry         string     `form:"expiry"`
+	Status         string     `form:"status"`
+	Domain         string     `form:"domain"`
+	MinAccessCount int64      `form:"min_access_count"`
+	Sort           string     `form:"sort"`
+	Limit          int        `form:"limit"`
+	Cursor         string     `form:"cursor"`
+}
+
+// UpdateShortLinkRequest 更新短链接请求，未提供的字段保持不变
+type UpdateShortLinkRequest struct {
+	URL         *string    `json:"url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ClearExpiry bool       `json:"clear_expires_at,omitempty"`
+}
+
+// DisableShortLinkRequest 停用短链接请求
+type DisableShortLinkRequest struct {
+	Reason string `json:"reason,omitempty"`
 }
 
 // CreateShortLinkResponse 创建短链接响应
@@ -29,15 +71,54 @@ type CreateShortLinkResponse struct {
 	OriginalURL string     `json:"original_url"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
+	Reused      bool       `json:"reused,omitempty"`
+}
+
+// BatchCreateShortLinkResult 批量创建中单个条目的结果，Index 对应请求中的位置
+type BatchCreateShortLinkResult struct {
+	Index  int                      `json:"index"`
+	Result *CreateShortLinkResponse `json:"result,omitempty"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+// BatchCreateShortLinkResponse 批量创建短链接响应
+type BatchCreateShortLinkResponse struct {
+	Results   []BatchCreateShortLinkResult `json:"results"`
+	Succeeded int                          `json:"succeeded"`
+	Failed    int                          `json:"failed"`
+}
+
+// ListShortLinksResponse 短链接列表响应，NextCursor 为空表示没有更多数据
+type ListShortLinksResponse struct {
+	Items      []*ShortLink `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// LookupShortLinksResponse 按原始URL反查的结果
+type LookupShortLinksResponse struct {
+	URL   string       `json:"url"`
+	Items []*ShortLink `json:"items"`
 }
 
 // ShortLinkInfo 短链接信息响应
 type ShortLinkInfo struct {
-	ShortCode   string     `json:"short_code"`
-	OriginalURL string     `json:"original_url"`
-	AccessCount int64      `json:"access_count"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ShortCode          string               `json:"short_code"`
+	OriginalURL        string               `json:"original_url"`
+	AccessCount        int64                `json:"access_count"`
+	StoredAccessCount  int64                `json:"stored_access_count"`
+	PendingAccessCount int64                `json:"pending_access_count"`
+	Status             string               `json:"status"`
+	StatusReason       string               `json:"status_reason,omitempty"`
+	UniqueVisitors     *UniqueVisitorCounts `json:"unique_visitors,omitempty"`
+	CreatedAt          time.Time            `json:"created_at"`
+	ExpiresAt          *time.Time           `json:"expires_at,omitempty"`
+}
+
+// UniqueVisitorCounts 独立访客数：当天、最近 7 天（含当天）和全部时间，按 UTC 日期划分
+type UniqueVisitorCounts struct {
+	Day     int64 `json:"day"`
+	Week    int64 `json:"week"`
+	AllTime int64 `json:"all_time"`
 }
 
 // IsExpired 检查短链接是否已过期
@@ -47,3 +128,76 @@ func (s *ShortLink) IsExpired() bool {
 	}
 	return time.Now().After(*s.ExpiresAt)
 }
+
+// ClickEvent 一次重定向产生的点击事件
+type ClickEvent struct {
+	ShortCode      string    `json:"short_code"`
+	ClickedAt      time.Time `json:"clicked_at"`
+	Referrer       string    `json:"referrer,omitempty"`
+	ReferrerDomain string    `json:"referrer_domain,omitempty"`
+	UserAgent      string    `json:"user_agent,omitempty"`
+	DeviceClass    string    `json:"device_class"`
+	ClientIP       string    `json:"client_ip,omitempty"`
+	AcceptLanguage string    `json:"accept_language,omitempty"`
+	// VisitorID 由客户端 IP 和 User-Agent 哈希得到的访客指纹，只用于独立访客计数
+	VisitorID string `json:"-"`
+}
+
+// LinkAnalyticsRequest 短链接分析查询参数
+type LinkAnalyticsRequest struct {
+	From     *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Interval string     `form:"interval"`
+}
+
+// LinkAnalytics 短链接分析结果：按时间分桶的点击序列以及来源、设备分布
+type LinkAnalytics struct {
+	ShortCode   string               `json:"short_code"`
+	From        time.Time            `json:"from"`
+	To          time.Time            `json:"to"`
+	Interval    string               `json:"interval"`
+	TotalClicks int64                `json:"total_clicks"`
+	Series      []AnalyticsPoint     `json:"series"`
+	Referrers   []AnalyticsBreakdown `json:"referrers"`
+	Devices     []AnalyticsBreakdown `json:"devices"`
+}
+
+// AnalyticsPoint 时间序列中的一个分桶，Time 为分桶起始时间（UTC）
+type AnalyticsPoint struct {
+	Time   time.Time `json:"time"`
+	Clicks int64     `json:"clicks"`
+}
+
+// AnalyticsBreakdown 按某个维度聚合的点击数
+type AnalyticsBreakdown struct {
+	Key    string `json:"key"`
+	Clicks int64  `json:"clicks"`
+}
+
+// StatsRequest 全局统计查询参数
+type StatsRequest struct {
+	Days int `form:"days"`
+	Top  int `form:"top"`
+}
+
+// StatsWindow 最近若干个自然日（UTC）的每日新建数、每日点击数和点击最多的短链接
+type StatsWindow struct {
+	From         time.Time    `json:"from"`
+	To           time.Time    `json:"to"`
+	Days         int          `json:"days"`
+	LinksCreated []DailyCount `json:"links_created"`
+	Clicks       []DailyCount `json:"clicks"`
+	TopLinks     []TopLink    `json:"top_links"`
+}
+
+// DailyCount 某一天（UTC）的计数
+type DailyCount struct {
+	Day   time.Time `json:"day"`
+	Count int64     `json:"count"`
+}
+
+// TopLink 时间窗口内的短链接点击数
+type TopLink struct {
+	ShortCode string `json:"short_code"`
+	Clicks    int64  `json:"clicks"`
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultFallbackCooldown 主限流器出错后直接使用降级限流器的时长，避免每个请求都等待 Redis 超时
+const defaultFallbackCooldown = 5 * time.Second
+
+// FallbackLimiter 主限流器（Redis）出错时改用降级限流器（进程内），冷却时间过后再尝试主限流器
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	cooldown time.Duration
+	logger   *zap.Logger
+
+	// retryAt 冷却结束时间（UnixNano），为 0 表示主限流器正常
+	retryAt atomic.Int64
+}
+
+func NewFallbackLimiter(primary, fallback Limiter, cooldown time.Duration, logger *zap.Logger) *FallbackLimiter {
+	if cooldown <= 0 {
+		cooldown = defaultFallbackCooldown
+	}
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+		cooldown: cooldown,
+		logger:   logger,
+	}
+}
+
+func (l *FallbackLimiter) Allow(ctx context.Context, policy Policy, subject string) (Result, error) {
+	now := time.Now()
+	if retryAt := l.retryAt.Load(); retryAt != 0 && now.UnixNano() < retryAt {
+		return l.fallback.Allow(ctx, policy, subject)
+	}
+
+	result, err := l.primary.Allow(ctx, policy, subject)
+	if err == nil {
+		if l.retryAt.Swap(0) != 0 {
+			l.logger.Info("rate limiter backend recovered")
+		}
+		return result, nil
+	}
+	// 客户端断开等请求自身的错误不代表后端故障
+	if ctx.Err() != nil {
+		return Result{}, err
+	}
+
+	if l.retryAt.Swap(now.Add(l.cooldown).UnixNano()) == 0 {
+		l.logger.Warn("rate limiter backend unavailable, using in-memory fallback",
+			zap.Error(err),
+			zap.Duration("cooldown", l.cooldown),
+		)
+	}
+	return l.fallback.Allow(ctx, policy, subject)
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval 清理过期计数的间隔
+const memorySweepInterval = time.Minute
+
+// windowCounter 一个主体在某策略下的固定窗口计数
+type windowCounter struct {
+	index    int64
+	current  int64
+	previous int64
+	window   time.Duration
+}
+
+// MemoryLimiter 进程内的滑动窗口限流，计数不在实例间共享，用于单实例部署和 Redis 不可用时的降级
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		counters:  make(map[string]*windowCounter),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, policy Policy, subject string) (Result, error) {
+	now := time.Now()
+	index, elapsed := windowPosition(now, policy.Window)
+	key := policy.Name + ":" + subject
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= memorySweepInterval {
+		l.sweep(now)
+	}
+
+	counter, ok := l.counters[key]
+	if !ok {
+		counter = &windowCounter{index: index, window: policy.Window}
+		l.counters[key] = counter
+	}
+	switch {
+	case counter.index == index-1:
+		counter.previous, counter.current = counter.current, 0
+	case counter.index != index:
+		counter.previous, counter.current = 0, 0
+	}
+	counter.index = index
+
+	allowed := estimate(counter.current, counter.previous, previousWeight(policy.Window, elapsed)) < policy.Limit
+	if allowed {
+		counter.current++
+	}
+
+	return newResult(policy, allowed, counter.current, counter.previous, elapsed), nil
+}
+
+// sweep 删除两个窗口内没有请求的计数
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, counter := range l.counters {
+		if index, _ := windowPosition(now, counter.window); index > counter.index+1 {
+			delete(l.counters, key)
+		}
+	}
+	l.lastSweep = now
+}
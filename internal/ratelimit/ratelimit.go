@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// 限流后端类型
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// Policy 限流策略：每个主体（API key 或 IP）在任意 Window 时长内最多 Limit 次请求
+type Policy struct {
+	// Name 路由组名称，不同策略的计数互不影响
+	Name   string
+	Limit  int64
+	Window time.Duration
+}
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed bool
+	Limit   int64
+	// Remaining 当前窗口内剩余可用次数
+	Remaining int64
+	// Reset 当前固定窗口结束的剩余时间
+	Reset time.Duration
+	// RetryAfter 被拒绝时至少需要等待的时间
+	RetryAfter time.Duration
+}
+
+// Limiter 滑动窗口限流器。
+// 使用滑动窗口计数近似：估算值 = 上一固定窗口计数 × 上一窗口在滑动窗口内的占比 + 当前固定窗口计数
+type Limiter interface {
+	// Allow 判断主体在策略下能否再发出一次请求，允许时计入一次
+	Allow(ctx context.Context, policy Policy, subject string) (Result, error)
+}
+
+var (
+	_ Limiter = (*RedisLimiter)(nil)
+	_ Limiter = (*MemoryLimiter)(nil)
+	_ Limiter = (*FallbackLimiter)(nil)
+)
+
+// windowPosition 返回 now 所在固定窗口的序号和已经过的时长
+func windowPosition(now time.Time, window time.Duration) (int64, time.Duration) {
+	nanos := now.UnixNano()
+	return nanos / int64(window), time.Duration(nanos % int64(window))
+}
+
+// previousWeight 上一固定窗口在滑动窗口内的占比
+func previousWeight(window, elapsed time.Duration) float64 {
+	return float64(window-elapsed) / float64(window)
+}
+
+// estimate 滑动窗口内的估算请求数
+func estimate(current, previous int64, weight float64) int64 {
+	return int64(math.Floor(float64(previous)*weight)) + current
+}
+
+// newResult 根据判断后的计数生成结果，允许时 current 已包含本次请求
+func newResult(policy Policy, allowed bool, current, previous int64, elapsed time.Duration) Result {
+	result := Result{
+		Allowed: allowed,
+		Limit:   policy.Limit,
+		Reset:   policy.Window - elapsed,
+	}
+
+	used := estimate(current, previous, previousWeight(policy.Window, elapsed))
+	if used < policy.Limit {
+		result.Remaining = policy.Limit - used
+	}
+	if !allowed {
+		result.RetryAfter = retryAfter(policy, current, previous, elapsed)
+	}
+	return result
+}
+
+// retryAfter 估算值降到 Limit 以下所需的时间
+func retryAfter(policy Policy, current, previous int64, elapsed time.Duration) time.Duration {
+	window := float64(policy.Window)
+	rest := window - float64(elapsed)
+	limit := float64(policy.Limit)
+
+	// 当前窗口计数未满，等待上一窗口的占比衰减即可
+	if current < policy.Limit && previous > 0 {
+		wait := rest - (limit-float64(current))*window/float64(previous)
+		return time.Duration(math.Max(wait, 0))
+	}
+
+	// 当前窗口已满，需要进入下一窗口并等待本窗口计数的占比衰减
+	wait := rest
+	if current > 0 {
+		wait += window * math.Max(1-limit/float64(current), 0)
+	}
+	return time.Duration(wait)
+}
@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindowPosition(t *testing.T) {
+	tests := []struct {
+		name        string
+		now         time.Time
+		window      time.Duration
+		wantIndex   int64
+		wantElapsed time.Duration
+	}{
+		{name: "window start", now: time.Unix(120, 0), window: time.Minute, wantIndex: 2, wantElapsed: 0},
+		{name: "inside window", now: time.Unix(125, 0), window: time.Minute, wantIndex: 2, wantElapsed: 5 * time.Second},
+		{name: "just before next window", now: time.Unix(179, 999), window: time.Minute, wantIndex: 2, wantElapsed: 59*time.Second + 999},
+		{name: "sub-second window", now: time.Unix(1, int64(750*time.Millisecond)), window: 500 * time.Millisecond, wantIndex: 3, wantElapsed: 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, elapsed := windowPosition(tt.now, tt.window)
+			if index != tt.wantIndex || elapsed != tt.wantElapsed {
+				t.Errorf("windowPosition() = (%d, %v), want (%d, %v)", index, elapsed, tt.wantIndex, tt.wantElapsed)
+			}
+		})
+	}
+}
+
+func TestSlidingWindowEstimate(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    int64
+		previous   int64
+		elapsed    time.Duration
+		wantWeight float64
+		want       int64
+	}{
+		{name: "window start counts all of previous", current: 0, previous: 10, elapsed: 0, wantWeight: 1, want: 10},
+		{name: "quarter through", current: 3, previous: 10, elapsed: 15 * time.Second, wantWeight: 0.75, want: 10},
+		{name: "half through rounds previous down", current: 2, previous: 5, elapsed: 30 * time.Second, wantWeight: 0.5, want: 4},
+		{name: "no previous window", current: 7, previous: 0, elapsed: 45 * time.Second, wantWeight: 0.25, want: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weight := previousWeight(time.Minute, tt.elapsed)
+			if weight != tt.wantWeight {
+				t.Fatalf("previousWeight() = %v, want %v", weight, tt.wantWeight)
+			}
+			if got := estimate(tt.current, tt.previous, weight); got != tt.want {
+				t.Errorf("estimate(%d, %d, %v) = %d, want %d", tt.current, tt.previous, weight, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	policy := Policy{Name: "test", Limit: 10, Window: time.Minute}
+
+	tests := []struct {
+		name     string
+		current  int64
+		previous int64
+		elapsed  time.Duration
+		want     time.Duration
+	}{
+		{name: "wait for previous window to decay", current: 5, previous: 10, elapsed: 15 * time.Second, want: 15 * time.Second},
+		{name: "previous already decayed enough", current: 4, previous: 10, elapsed: 30 * time.Second, want: 0},
+		{name: "current window full", current: 10, previous: 0, elapsed: 15 * time.Second, want: 45 * time.Second},
+		{name: "current window full ignores previous", current: 10, previous: 4, elapsed: 15 * time.Second, want: 45 * time.Second},
+		{name: "over limit waits into next window", current: 20, previous: 0, elapsed: 30 * time.Second, want: 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(policy, tt.current, tt.previous, tt.elapsed); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	policy := Policy{Name: "test", Limit: 10, Window: time.Minute}
+
+	tests := []struct {
+		name           string
+		allowed        bool
+		current        int64
+		previous       int64
+		elapsed        time.Duration
+		wantRemaining  int64
+		wantReset      time.Duration
+		wantRetryAfter time.Duration
+	}{
+		{name: "allowed", allowed: true, current: 3, previous: 0, elapsed: 10 * time.Second, wantRemaining: 7, wantReset: 50 * time.Second},
+		{name: "allowed with previous", allowed: true, current: 3, previous: 8, elapsed: 30 * time.Second, wantRemaining: 3, wantReset: 30 * time.Second},
+		{name: "denied", allowed: false, current: 5, previous: 10, elapsed: 15 * time.Second, wantRemaining: 0, wantReset: 45 * time.Second, wantRetryAfter: 15 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newResult(policy, tt.allowed, tt.current, tt.previous, tt.elapsed)
+			if result.Allowed != tt.allowed || result.Limit != policy.Limit {
+				t.Errorf("Allowed, Limit = %v, %d; want %v, %d", result.Allowed, result.Limit, tt.allowed, policy.Limit)
+			}
+			if result.Remaining != tt.wantRemaining {
+				t.Errorf("Remaining = %d, want %d", result.Remaining, tt.wantRemaining)
+			}
+			if result.Reset != tt.wantReset {
+				t.Errorf("Reset = %v, want %v", result.Reset, tt.wantReset)
+			}
+			if result.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("RetryAfter = %v, want %v", result.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestMemoryLimiterAllow(t *testing.T) {
+	// 窗口足够长，测试期间不会跨越固定窗口
+	const window = 1000 * time.Hour
+
+	tests := []struct {
+		name        string
+		limit       int64
+		requests    int
+		wantAllowed int
+	}{
+		{name: "under limit", limit: 5, requests: 3, wantAllowed: 3},
+		{name: "at limit", limit: 5, requests: 5, wantAllowed: 5},
+		{name: "over limit", limit: 5, requests: 8, wantAllowed: 5},
+		{name: "limit of one", limit: 1, requests: 3, wantAllowed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter := NewMemoryLimiter()
+			policy := Policy{Name: "test", Limit: tt.limit, Window: window}
+
+			allowed := 0
+			var last Result
+			for i := 0; i < tt.requests; i++ {
+				result, err := limiter.Allow(ctx, policy, "ip:127.0.0.1")
+				if err != nil {
+					t.Fatalf("Allow() error = %v", err)
+				}
+				if result.Allowed {
+					allowed++
+				}
+				last = result
+			}
+
+			if allowed != tt.wantAllowed {
+				t.Errorf("allowed %d requests, want %d", allowed, tt.wantAllowed)
+			}
+			if wantRemaining := tt.limit - int64(tt.wantAllowed); last.Remaining != wantRemaining {
+				t.Errorf("last Remaining = %d, want %d", last.Remaining, wantRemaining)
+			}
+			if tt.requests > tt.wantAllowed && last.RetryAfter <= 0 {
+				t.Errorf("denied result RetryAfter = %v, want > 0", last.RetryAfter)
+			}
+
+			// 其他主体和其他策略各自计数
+			if result, _ := limiter.Allow(ctx, policy, "ip:127.0.0.2"); !result.Allowed {
+				t.Error("other subject was limited")
+			}
+			other := Policy{Name: "other", Limit: tt.limit, Window: window}
+			if result, _ := limiter.Allow(ctx, other, "ip:127.0.0.1"); !result.Allowed {
+				t.Error("other policy was limited")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/cache"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const keyPrefix = "ratelimit:"
+
+// slidingWindowScript 原子地读取当前和上一固定窗口的计数，估算值未达到上限时计入一次。
+// KEYS[1] 当前窗口计数，KEYS[2] 上一窗口计数；ARGV[1] 上限，ARGV[2] 上一窗口占比，ARGV[3] 计数过期时间（毫秒）。
+// 返回 {是否允许, 当前窗口计数, 上一窗口计数}
+var slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+
+if math.floor(previous * weight) + current >= limit then
+	return {0, current, previous}
+end
+
+current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, current, previous}
+`)
+
+// RedisLimiter 基于 Redis Lua 脚本的滑动窗口限流，多实例共享计数。
+// 计数键为 ratelimit:{<策略>:<主体>}:<窗口序号>，同一主体的键在 Redis Cluster 中位于同一槽位
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisLimiter(redisClient *cache.RedisClient) *RedisLimiter {
+	return &RedisLimiter{client: redisClient.GetClient()}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, policy Policy, subject string) (Result, error) {
+	index, elapsed := windowPosition(time.Now(), policy.Window)
+	prefix := fmt.Sprintf("%s{%s:%s}:", keyPrefix, policy.Name, subject)
+	keys := []string{
+		prefix + strconv.FormatInt(index, 10),
+		prefix + strconv.FormatInt(index-1, 10),
+	}
+	weight := strconv.FormatFloat(previousWeight(policy.Window, elapsed), 'f', 6, 64)
+	// 计数在下一窗口中仍作为上一窗口使用，保留两个窗口
+	ttl := (2 * policy.Window).Milliseconds()
+
+	values, err := slidingWindowScript.Run(ctx, l.client, keys, policy.Limit, weight, ttl).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	return newResult(policy, values[0] == 1, values[1], values[2], elapsed), nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"short-url/internal/cache"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 访问计数缓冲后端类型
+const (
+	AccessCounterRedis  = "redis"
+	AccessCounterMemory = "memory"
+)
+
+// AccessCounter 访问计数缓冲区，点击先在此累加，再由后台任务批量写回数据库
+type AccessCounter interface {
+	// Incr 为短码累加一次访问
+	Incr(ctx context.Context, shortCode string) error
+	// Pending 返回短码尚未写回数据库的增量
+	Pending(ctx context.Context, shortCode string) (int64, error)
+	// Drain 原子地取出并清空全部待写回增量
+	Drain(ctx context.Context) (map[string]int64, error)
+	// Restore 写回失败时将增量放回缓冲区
+	Restore(ctx context.Context, deltas map[string]int64) error
+}
+
+var (
+	_ AccessCounter = (*RedisAccessCounter)(nil)
+	_ AccessCounter = (*MemoryAccessCounter)(nil)
+)
+
+const accessCountPendingKey = "access_counts:pending"
+
+// redisDrainScript 读取并删除待写回哈希，保证多实例下每个增量只被取出一次
+var redisDrainScript = redis.NewScript(`
+local values = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return values
+`)
+
+// RedisAccessCounter 基于 Redis 哈希（HINCRBY）的访问计数缓冲，多实例共享
+type RedisAccessCounter struct {
+	client *redis.Client
+}
+
+func NewRedisAccessCounter(redisClient *cache.RedisClient) *RedisAccessCounter {
+	return &RedisAccessCounter{client: redisClient.GetClient()}
+}
+
+func (c *RedisAccessCounter) Incr(ctx context.Context, shortCode string) error {
+	return c.client.HIncrBy(ctx, accessCountPendingKey, shortCode, 1).Err()
+}
+
+func (c *RedisAccessCounter) Pending(ctx context.Context, shortCode string) (int64, error) {
+	pending, err := c.client.HGet(ctx, accessCountPendingKey, shortCode).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return pending, err
+}
+
+func (c *RedisAccessCounter) Drain(ctx context.Context) (map[string]int64, error) {
+	values, err := redisDrainScript.Run(ctx, c.client, []string{accessCountPendingKey}).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to drain access counts: %w", err)
+	}
+
+	deltas := make(map[string]int64, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		delta, err := strconv.ParseInt(values[i+1], 10, 64)
+		if err != nil {
+			continue
+		}
+		deltas[values[i]] = delta
+	}
+
+	return deltas, nil
+}
+
+func (c *RedisAccessCounter) Restore(ctx context.Context, deltas map[string]int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for shortCode, delta := range deltas {
+			pipe.HIncrBy(ctx, accessCountPendingKey, shortCode, delta)
+		}
+		return nil
+	})
+	return err
+}
+
+const accessCounterShards = 32
+
+// MemoryAccessCounter 分片的进程内访问计数缓冲
+type MemoryAccessCounter struct {
+	shards [accessCounterShards]accessCounterShard
+}
+
+type accessCounterShard struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewMemoryAccessCounter() *MemoryAccessCounter {
+	c := &MemoryAccessCounter{}
+	for i := range c.shards {
+		c.shards[i].counts = make(map[string]int64)
+	}
+	return c
+}
+
+func (c *MemoryAccessCounter) Incr(ctx context.Context, shortCode string) error {
+	c.add(shortCode, 1)
+	return nil
+}
+
+func (c *MemoryAccessCounter) Pending(ctx context.Context, shortCode string) (int64, error) {
+	shard := c.shard(shortCode)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	return shard.counts[shortCode], nil
+}
+
+func (c *MemoryAccessCounter) Drain(ctx context.Context) (map[string]int64, error) {
+	deltas := make(map[string]int64)
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mu.Lock()
+		counts := shard.counts
+		shard.counts = make(map[string]int64)
+		shard.mu.Unlock()
+
+		for shortCode, delta := range counts {
+			deltas[shortCode] = delta
+		}
+	}
+	return deltas, nil
+}
+
+func (c *MemoryAccessCounter) Restore(ctx context.Context, deltas map[string]int64) error {
+	for shortCode, delta := range deltas {
+		c.add(shortCode, delta)
+	}
+	return nil
+}
+
+func (c *MemoryAccessCounter) add(shortCode string, delta int64) {
+	shard := c.shard(shortCode)
+	shard.mu.Lock()
+	shard.counts[shortCode] += delta
+	shard.mu.Unlock()
+}
+
+func (c *MemoryAccessCounter) shard(shortCode string) *accessCounterShard {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(shortCode))
+	return &c.shards[hasher.Sum32()%accessCounterShards]
+}
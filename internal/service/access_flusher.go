@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAccessCountFlushInterval = 5 * time.Second
+	defaultAccessCountBatchSize     = 1000
+	accessCountFlushTimeout         = 30 * time.Second
+)
+
+// FlushAccessCounts 将缓冲区中的访问增量分批写回数据库，写回失败的部分放回缓冲区
+func (s *ShortLinkService) FlushAccessCounts(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "FlushAccessCounts")
+	defer span.End()
+
+	deltas, err := s.accessCounter.Drain(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(deltas) == 0 {
+		return 0, nil
+	}
+
+	batchSize := s.config.AccessCount.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultAccessCountBatchSize
+	}
+
+	// 已写回的短码从 deltas 中删除，失败时剩余部分全部放回缓冲区
+	var flushed int64
+	batch := make(map[string]int64, batchSize)
+	writeBatch := func() error {
+		if err := s.repo.IncrementAccessCounts(ctx, batch); err != nil {
+			s.restoreAccessCounts(deltas)
+			return err
+		}
+		for shortCode, delta := range batch {
+			flushed += delta
+			delete(deltas, shortCode)
+		}
+		batch = make(map[string]int64, batchSize)
+		return nil
+	}
+
+	for shortCode, delta := range deltas {
+		batch[shortCode] = delta
+		if len(batch) >= batchSize {
+			if err := writeBatch(); err != nil {
+				return flushed, err
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := writeBatch(); err != nil {
+			return flushed, err
+		}
+	}
+
+	return flushed, nil
+}
+
+// restoreAccessCounts 将未写回的增量放回缓冲区
+func (s *ShortLinkService) restoreAccessCounts(deltas map[string]int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), accessCountFlushTimeout)
+	defer cancel()
+
+	if err := s.accessCounter.Restore(ctx, deltas); err != nil {
+		s.loggerFor(ctx).Error("failed to restore access counts, clicks lost",
+			zap.Int("links", len(deltas)), zap.Error(err))
+	}
+}
+
+// runAccessCountFlusher 周期性写回访问计数，停止时做最后一次写回
+func (s *ShortLinkService) runAccessCountFlusher(interval time.Duration) {
+	defer s.workers.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			s.flushAccessCounts()
+			return
+		case <-ticker.C:
+			s.flushAccessCounts()
+		}
+	}
+}
+
+func (s *ShortLinkService) flushAccessCounts() {
+	ctx, cancel := context.WithTimeout(context.Background(), accessCountFlushTimeout)
+	defer cancel()
+
+	flushed, err := s.FlushAccessCounts(ctx)
+	if err != nil {
+		s.logger.Error("failed to flush access counts", zap.Error(err))
+		return
+	}
+	if flushed > 0 {
+		s.logger.Debug("flushed access counts", zap.Int64("clicks", flushed))
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"short-url/internal/config"
+	"short-url/internal/models"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+var errFlushFailed = errors.New("flush failed")
+
+// failingFlushStore 前 okBatches 次批量写回成功，之后全部失败
+type failingFlushStore struct {
+	*MemoryRepository
+	okBatches int
+	calls     int
+}
+
+func (s *failingFlushStore) IncrementAccessCounts(ctx context.Context, deltas map[string]int64) error {
+	s.calls++
+	if s.calls > s.okBatches {
+		return errFlushFailed
+	}
+	return s.MemoryRepository.IncrementAccessCounts(ctx, deltas)
+}
+
+func TestFlushAccessCountsRestoresOnFailure(t *testing.T) {
+	const links = 10
+
+	tests := []struct {
+		name      string
+		batchSize int
+		okBatches int
+		wantErr   bool
+	}{
+		{name: "all batches succeed", batchSize: 3, okBatches: links, wantErr: false},
+		{name: "single batch fails", batchSize: links, okBatches: 0, wantErr: true},
+		{name: "first batch fails", batchSize: 3, okBatches: 0, wantErr: true},
+		{name: "fails midway", batchSize: 3, okBatches: 2, wantErr: true},
+		{name: "last batch fails", batchSize: 3, okBatches: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := &failingFlushStore{MemoryRepository: NewMemoryRepository(), okBatches: tt.okBatches}
+			counter := NewMemoryAccessCounter()
+
+			clicks := make(map[string]int64, links)
+			var total int64
+			for i := 0; i < links; i++ {
+				code := fmt.Sprintf("code%02d", i)
+				if err := store.CreateShortLink(ctx, &models.ShortLink{ShortCode: code, OriginalURL: "https://example.com/" + code}); err != nil {
+					t.Fatalf("CreateShortLink() error = %v", err)
+				}
+				clicks[code] = int64(i + 1)
+				total += int64(i + 1)
+				for j := 0; j <= i; j++ {
+					counter.Incr(ctx, code)
+				}
+			}
+
+			cfg := &config.Config{AccessCount: config.AccessCountConfig{BatchSize: tt.batchSize}}
+			svc := NewShortLinkService(store, nil, nil, counter, nil, cfg, zap.NewNop())
+
+			flushed, err := svc.FlushAccessCounts(ctx)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("FlushAccessCounts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errFlushFailed) {
+				t.Errorf("FlushAccessCounts() error = %v, want %v", err, errFlushFailed)
+			}
+
+			// 每个短码的点击要么已写入数据库，要么仍在缓冲区，不丢也不重复
+			var written int64
+			for code, want := range clicks {
+				link, err := store.GetShortLinkByCode(ctx, code)
+				if err != nil {
+					t.Fatalf("GetShortLinkByCode(%q) error = %v", code, err)
+				}
+				pending, err := counter.Pending(ctx, code)
+				if err != nil {
+					t.Fatalf("Pending(%q) error = %v", code, err)
+				}
+				if link.AccessCount+pending != want {
+					t.Errorf("%s: written %d + pending %d, want %d", code, link.AccessCount, pending, want)
+				}
+				if link.AccessCount != 0 && pending != 0 {
+					t.Errorf("%s: both written (%d) and pending (%d)", code, link.AccessCount, pending)
+				}
+				written += link.AccessCount
+			}
+
+			if flushed != written {
+				t.Errorf("FlushAccessCounts() = %d, but %d clicks were written", flushed, written)
+			}
+			if !tt.wantErr && flushed != total {
+				t.Errorf("FlushAccessCounts() = %d, want %d", flushed, total)
+			}
+
+			// 数据库恢复后，放回缓冲区的增量在下一次写回时补上
+			store.okBatches = store.calls + links
+			retried, err := svc.FlushAccessCounts(ctx)
+			if err != nil {
+				t.Fatalf("retry FlushAccessCounts() error = %v", err)
+			}
+			if flushed+retried != total {
+				t.Errorf("flushed %d + retried %d, want %d", flushed, retried, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"short-url/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultAnalyticsRange = 7 * 24 * time.Hour
+	maxAnalyticsBuckets   = 1000
+	analyticsTopN         = 10
+
+	// directReferrer 没有来源页面的点击在分布中使用的键
+	directReferrer = "direct"
+)
+
+// GetLinkAnalytics 获取短链接在时间范围内的点击序列和来源、设备分布。
+// 默认统计最近 7 天；未指定粒度时范围超过 3 天按天分桶，否则按小时
+func (s *ShortLinkService) GetLinkAnalytics(ctx context.Context, shortCode string, req *models.LinkAnalyticsRequest) (*models.LinkAnalytics, error) {
+	ctx, span := startSpan(ctx, "GetLinkAnalytics", shortCodeAttr(shortCode))
+	defer span.End()
+
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = req.To.UTC()
+	}
+	from := to.Add(-defaultAnalyticsRange)
+	if req.From != nil {
+		from = req.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	}
+
+	interval := req.Interval
+	switch interval {
+	case "":
+		interval = AnalyticsIntervalHour
+		if to.Sub(from) > 3*24*time.Hour {
+			interval = AnalyticsIntervalDay
+		}
+	case AnalyticsIntervalHour, AnalyticsIntervalDay:
+	default:
+		return nil, fmt.Errorf("%w: interval must be %q or %q", ErrInvalidQuery, AnalyticsIntervalHour, AnalyticsIntervalDay)
+	}
+
+	step := intervalDuration(interval)
+	if to.Sub(truncateToInterval(from, interval))/step > maxAnalyticsBuckets {
+		return nil, fmt.Errorf("%w: at most %d buckets are allowed", ErrInvalidQuery, maxAnalyticsBuckets)
+	}
+
+	if _, err := s.repo.GetShortLinkByCode(ctx, shortCode); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShortCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get short link: %w", err)
+	}
+
+	analytics, err := s.repo.GetClickAnalytics(ctx, AnalyticsQuery{
+		ShortCode: shortCode,
+		From:      from,
+		To:        to,
+		Interval:  interval,
+		TopN:      analyticsTopN,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click analytics: %w", err)
+	}
+
+	analytics.Series = fillSeries(analytics.Series, from, to, interval)
+	for i := range analytics.Referrers {
+		if analytics.Referrers[i].Key == "" {
+			analytics.Referrers[i].Key = directReferrer
+		}
+	}
+	if analytics.Referrers == nil {
+		analytics.Referrers = []models.AnalyticsBreakdown{}
+	}
+	if analytics.Devices == nil {
+		analytics.Devices = []models.AnalyticsBreakdown{}
+	}
+
+	return analytics, nil
+}
+
+// fillSeries 为没有点击的分桶补零，返回覆盖整个时间范围的连续序列
+func fillSeries(points []models.AnalyticsPoint, from, to time.Time, interval string) []models.AnalyticsPoint {
+	clicks := make(map[time.Time]int64, len(points))
+	for _, point := range points {
+		clicks[point.Time] = point.Clicks
+	}
+
+	step := intervalDuration(interval)
+	series := make([]models.AnalyticsPoint, 0, to.Sub(from)/step+1)
+	for bucket := truncateToInterval(from, interval); bucket.Before(to); bucket = bucket.Add(step) {
+		series = append(series, models.AnalyticsPoint{Time: bucket, Clicks: clicks[bucket]})
+	}
+
+	return series
+}
+
+func intervalDuration(interval string) time.Duration {
+	if interval == AnalyticsIntervalDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"short-url/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidAPIKey        = errors.New("invalid API key")
+	ErrAPIKeyNotFound       = errors.New("API key not found")
+	ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+)
+
+const (
+	// apiKeyPrefix 签发的 API key 均以此开头，其余为 32 字节随机数的 base64url 编码
+	apiKeyPrefix = "su_"
+	// apiKeyLength 前缀加 32 字节随机数 base64url 编码（43 个字符）后的总长度
+	apiKeyLength = len(apiKeyPrefix) + 43
+	// apiKeyDisplayLength 保存和显示的明文前缀长度
+	apiKeyDisplayLength = 11
+	maxAPIKeyNameLength = 100
+
+	defaultAPIKeyCacheTTL = time.Minute
+)
+
+// adminTokenKey ADMIN_TOKEN 对应的内置 API key，不保存在数据库中，用于签发第一个 key 之前和内存存储下访问管理员接口
+var adminTokenKey = models.APIKey{
+	Name:   "admin-token",
+	Scopes: []string{models.ScopeAdmin},
+}
+
+// APIKeyService 签发、吊销和校验 API key。
+// 校验通过的 key 在进程内缓存 cacheTTL，吊销在其他实例上最多延迟 cacheTTL 生效
+type APIKeyService struct {
+	store      APIKeyStore
+	adminToken string
+	cacheTTL   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedAPIKey
+}
+
+type cachedAPIKey struct {
+	key       *models.APIKey
+	expiresAt time.Time
+}
+
+func NewAPIKeyService(store APIKeyStore, adminToken string, cacheTTL time.Duration) *APIKeyService {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultAPIKeyCacheTTL
+	}
+	return &APIKeyService{
+		store:      store,
+		adminToken: adminToken,
+		cacheTTL:   cacheTTL,
+		cache:      make(map[string]cachedAPIKey),
+	}
+}
+
+// IssueAPIKey 签发 API key，返回元数据和只显示一次的明文
+func (s *APIKeyService) IssueAPIKey(ctx context.Context, name string, scopes []string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidAPIKeyRequest, maxAPIKeyNameLength)
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &models.APIKey{
+		Name:   name,
+		Prefix: rawKey[:apiKeyDisplayLength],
+		Scopes: scopes,
+	}
+	if err := s.store.CreateAPIKey(ctx, key, hashAPIKey(rawKey)); err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+// Authenticate 校验明文 API key，无效或已吊销时返回 ErrInvalidAPIKey
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if s.adminToken != "" && subtle.ConstantTimeCompare([]byte(rawKey), []byte(s.adminToken)) == 1 {
+		key := adminTokenKey
+		return &key, nil
+	}
+	// 格式不符的 key 不可能存在，无需查询数据库
+	if !validAPIKeyFormat(rawKey) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	keyHash := hashAPIKey(rawKey)
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[keyHash]
+	if ok && now.After(cached.expiresAt) {
+		delete(s.cache, keyHash)
+		ok = false
+	}
+	s.mu.Unlock()
+	if ok {
+		return cached.key, nil
+	}
+
+	key, err := s.store.AuthenticateAPIKey(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[keyHash] = cachedAPIKey{key: key, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return key, nil
+}
+
+// RevokeAPIKey 吊销 API key，本实例的缓存立即失效
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id int64) error {
+	if err := s.store.RevokeAPIKey(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %d", ErrAPIKeyNotFound, id)
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	for keyHash, cached := range s.cache {
+		if cached.key.ID == id {
+			delete(s.cache, keyHash)
+		}
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// ListAPIKeys 列出全部 API key
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.store.ListAPIKeys(ctx)
+}
+
+// normalizeScopes 校验权限并去重，按 create、read、admin 的顺序返回
+func normalizeScopes(scopes []string) ([]string, error) {
+	requested := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		switch scope {
+		case models.ScopeCreate, models.ScopeRead, models.ScopeAdmin:
+			requested[scope] = true
+		default:
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+
+	var normalized []string
+	for _, scope := range []string{models.ScopeCreate, models.ScopeRead, models.ScopeAdmin} {
+		if requested[scope] {
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	return normalized, nil
+}
+
+// validAPIKeyFormat 检查 key 是否符合签发格式：固定前缀、固定长度、base64url 字符
+func validAPIKeyFormat(rawKey string) bool {
+	if len(rawKey) != apiKeyLength || !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return false
+	}
+	for _, c := range rawKey[len(apiKeyPrefix):] {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// hashAPIKey 计算 API key 的 SHA-256 哈希。key 为 256 位随机数，无需加盐或慢哈希
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyColumns 查询 API key 时的列顺序，与 scanAPIKey 对应
+const apiKeyColumns = `id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+// CreateAPIKey 保存 API key，成功后回填 ID 与创建时间
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	if err := r.db.Pool.QueryRow(ctx, query, key.Name, key.Prefix, keyHash, key.Scopes).Scan(&key.ID, &key.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey 查找未吊销的 API key 并更新最近使用时间
+func (r *Repository) AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	return key, nil
+}
+
+// RevokeAPIKey 吊销 API key
+func (r *Repository) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("failed to revoke API key: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// ListAPIKeys 按 ID 升序列出全部 API key，包括已吊销的
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
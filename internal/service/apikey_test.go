@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"short-url/internal/models"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashAPIKey(t *testing.T) {
+	tests := []struct {
+		rawKey string
+		want   string
+	}{
+		{rawKey: "", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{rawKey: "abc", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{rawKey: "su_test", want: "75749571be92c19fbe8c31863af33b96a50bfb920cf04e918048764a21ad57c0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawKey, func(t *testing.T) {
+			if got := hashAPIKey(tt.rawKey); got != tt.want {
+				t.Errorf("hashAPIKey(%q) = %s, want %s", tt.rawKey, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidAPIKeyFormat(t *testing.T) {
+	body := strings.Repeat("aZ09-_", 7) + "x"
+
+	tests := []struct {
+		name   string
+		rawKey string
+		want   bool
+	}{
+		{name: "valid", rawKey: apiKeyPrefix + body, want: true},
+		{name: "empty", rawKey: "", want: false},
+		{name: "prefix only", rawKey: apiKeyPrefix, want: false},
+		{name: "wrong prefix", rawKey: "sk_" + body, want: false},
+		{name: "too short", rawKey: apiKeyPrefix + body[1:], want: false},
+		{name: "too long", rawKey: apiKeyPrefix + body + "x", want: false},
+		{name: "padding", rawKey: apiKeyPrefix + body[1:] + "=", want: false},
+		{name: "standard base64 characters", rawKey: apiKeyPrefix + body[1:] + "+", want: false},
+		{name: "multibyte character", rawKey: apiKeyPrefix + body[3:] + "é", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validAPIKeyFormat(tt.rawKey); got != tt.want {
+				t.Errorf("validAPIKeyFormat(%q) = %v, want %v", tt.rawKey, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single", scopes: []string{"read"}, want: []string{"read"}},
+		{name: "ordered and deduplicated", scopes: []string{"admin", "read", "create", "read"}, want: []string{"create", "read", "admin"}},
+		{name: "trims spaces", scopes: []string{" create "}, want: []string{"create"}},
+		{name: "unknown scope", scopes: []string{"read", "delete"}, wantErr: true},
+		{name: "case sensitive", scopes: []string{"Read"}, wantErr: true},
+		{name: "empty", scopes: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeScopes(tt.scopes)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAPIKeyRequest) {
+					t.Errorf("normalizeScopes(%v) error = %v, want ErrInvalidAPIKeyRequest", tt.scopes, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeScopes(%v) error = %v", tt.scopes, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeScopes(%v) = %v, want %v", tt.scopes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(NewMemoryRepository(), "s3cret", 0)
+
+	_, readKey, err := svc.IssueAPIKey(ctx, "reader", []string{models.ScopeRead})
+	if err != nil {
+		t.Fatalf("IssueAPIKey() error = %v", err)
+	}
+	revoked, revokedKey, err := svc.IssueAPIKey(ctx, "revoked", []string{models.ScopeCreate})
+	if err != nil {
+		t.Fatalf("IssueAPIKey() error = %v", err)
+	}
+	// 先校验一次让 key 进入缓存，吊销后缓存也必须失效
+	if _, err := svc.Authenticate(ctx, revokedKey); err != nil {
+		t.Fatalf("Authenticate() before revoke error = %v", err)
+	}
+	if err := svc.RevokeAPIKey(ctx, revoked.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		rawKey     string
+		wantName   string
+		wantScopes []string
+		wantErr    bool
+	}{
+		{name: "admin token", rawKey: "s3cret", wantName: "admin-token", wantScopes: []string{models.ScopeAdmin}},
+		{name: "issued key", rawKey: readKey, wantName: "reader", wantScopes: []string{models.ScopeRead}},
+		{name: "revoked key", rawKey: revokedKey, wantErr: true},
+		{name: "empty", rawKey: "", wantErr: true},
+		{name: "malformed", rawKey: "not-a-key", wantErr: true},
+		{name: "admin token prefix", rawKey: "s3cre", wantErr: true},
+		{name: "well-formed but unknown", rawKey: apiKeyPrefix + strings.Repeat("A", apiKeyLength-len(apiKeyPrefix)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := svc.Authenticate(ctx, tt.rawKey)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAPIKey) {
+					t.Errorf("Authenticate(%q) error = %v, want ErrInvalidAPIKey", tt.rawKey, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate(%q) error = %v", tt.rawKey, err)
+			}
+			if key.Name != tt.wantName || !slices.Equal(key.Scopes, tt.wantScopes) {
+				t.Errorf("Authenticate(%q) = %s %v, want %s %v", tt.rawKey, key.Name, key.Scopes, tt.wantName, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func TestAPIKeyServiceWithoutAdminToken(t *testing.T) {
+	svc := NewAPIKeyService(NewMemoryRepository(), "", 0)
+
+	// 未配置 ADMIN_TOKEN 时空字符串不能当作管理员令牌
+	if _, err := svc.Authenticate(context.Background(), ""); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate(\"\") error = %v, want ErrInvalidAPIKey", err)
+	}
+}
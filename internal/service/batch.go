@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/models"
+	"short-url/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchMaxItems = 1000
+	batchMaxRetries      = 10
+)
+
+// BatchCreateShortLinks 批量创建短链接。单个条目的校验失败或短码冲突只影响该条目，
+// 结果按请求顺序逐条返回；整体失败仅在请求本身无效时发生。
+func (s *ShortLinkService) BatchCreateShortLinks(ctx context.Context, req *models.BatchCreateShortLinkRequest) (*models.BatchCreateShortLinkResponse, error) {
+	ctx, span := startSpan(ctx, "BatchCreateShortLinks")
+	defer span.End()
+
+	maxItems := s.config.App.BatchMaxItems
+	if maxItems <= 0 {
+		maxItems = defaultBatchMaxItems
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: items must not be empty", ErrInvalidBatch)
+	}
+	if len(req.Items) > maxItems {
+		return nil, fmt.Errorf("%w: at most %d items are allowed", ErrInvalidBatch, maxItems)
+	}
+
+	results := make([]models.BatchCreateShortLinkResult, len(req.Items))
+	links := make([]*models.ShortLink, len(req.Items))
+	taken := make(map[string]bool)
+
+	// 逐条校验，自定义短码在批次内去重
+	var pending []int
+	for i, item := range req.Items {
+		results[i].Index = i
+
+		if !utils.IsValidURL(item.URL) {
+			results[i].Error = ErrInvalidURL.Error()
+			continue
+		}
+		if item.CustomCode != "" {
+			if !utils.IsValidShortCode(item.CustomCode) {
+				results[i].Error = "invalid custom code format"
+				continue
+			}
+			if taken[item.CustomCode] {
+				results[i].Error = ErrShortCodeExists.Error()
+				continue
+			}
+			taken[item.CustomCode] = true
+		}
+
+		normalizedURL := utils.NormalizeURL(item.URL)
+		if item.ReuseExisting && item.CustomCode == "" {
+			if existing := s.findReusableLink(ctx, normalizedURL); existing != nil {
+				results[i].Result = s.newCreateResponse(existing)
+				results[i].Result.Reused = true
+				continue
+			}
+		}
+
+		links[i] = &models.ShortLink{
+			ShortCode:   item.CustomCode,
+			OriginalURL: normalizedURL,
+			ExpiresAt:   item.ExpiresAt,
+		}
+		pending = append(pending, i)
+	}
+
+	// 插入时跳过冲突的短码：自定义短码直接报告冲突，生成的短码换一个重试
+	var created []*models.ShortLink
+	for attempt := 0; len(pending) > 0 && attempt < batchMaxRetries; attempt++ {
+		if err := s.assignShortCodes(ctx, links, pending, taken); err != nil {
+			s.loggerFor(ctx).Error("failed to generate short codes", zap.Error(err))
+			break
+		}
+
+		batch := make([]*models.ShortLink, len(pending))
+		for j, i := range pending {
+			batch[j] = links[i]
+		}
+
+		inserted, err := s.repo.CreateShortLinks(ctx, batch)
+		if err != nil {
+			s.loggerFor(ctx).Error("failed to save short links", zap.Error(err), zap.Int("count", len(batch)))
+			for _, i := range pending {
+				results[i].Error = "failed to save short link"
+			}
+			pending = nil
+			break
+		}
+
+		var retry []int
+		for j, i := range pending {
+			switch {
+			case inserted[j]:
+				created = append(created, links[i])
+				results[i].Result = s.newCreateResponse(links[i])
+			case req.Items[i].CustomCode != "":
+				results[i].Error = ErrShortCodeExists.Error()
+			default:
+				links[i].ShortCode = ""
+				retry = append(retry, i)
+				s.generationStats.Retries.Add(1)
+			}
+		}
+		pending = retry
+	}
+	for _, i := range pending {
+		results[i].Error = "failed to generate short code"
+	}
+
+	if len(created) > 0 {
+		codes := make([]string, len(created))
+		for i, shortLink := range created {
+			codes[i] = shortLink.ShortCode
+		}
+
+		if _, err := s.bloomFilter.MAdd(ctx, codes); err != nil {
+			s.loggerFor(ctx).Warn("failed to add to bloom filter", zap.Error(err))
+		}
+		// 与单个创建相同，先广播失效以淘汰其他实例 L1 中的负缓存
+		for _, code := range codes {
+			s.invalidateLink(ctx, code)
+		}
+		if err := s.cacheLinks(ctx, created); err != nil {
+			s.loggerFor(ctx).Warn("failed to cache short links", zap.Error(err))
+		}
+	}
+
+	response := &models.BatchCreateShortLinkResponse{Results: results}
+	for _, result := range results {
+		if result.Error != "" {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+
+	return response, nil
+}
+
+// assignShortCodes 为 pending 中尚无短码的条目分配生成的短码
+func (s *ShortLinkService) assignShortCodes(ctx context.Context, links []*models.ShortLink, pending []int, taken map[string]bool) error {
+	var missing []int
+	for _, i := range pending {
+		if links[i].ShortCode == "" {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	codes, err := s.generateShortCodes(ctx, len(missing), taken)
+	if err != nil {
+		return err
+	}
+	for j, i := range missing {
+		links[i].ShortCode = codes[j]
+	}
+
+	return nil
+}
+
+// generateShortCodes 批量生成 n 个短码，用一次 MExists 过滤布隆过滤器中可能已存在的候选。
+// 布隆过滤器不可用时直接返回候选短码，冲突由插入时的唯一约束兜底。
+func (s *ShortLinkService) generateShortCodes(ctx context.Context, n int, taken map[string]bool) ([]string, error) {
+	codes := make([]string, 0, n)
+
+	for attempt := 0; len(codes) < n && attempt < batchMaxRetries; attempt++ {
+		candidates := make([]string, 0, n-len(codes))
+		for len(candidates) < cap(candidates) {
+			shortCode, err := s.encoder.GenerateRandomCode()
+			if err != nil {
+				return nil, err
+			}
+			if taken[shortCode] {
+				continue
+			}
+			taken[shortCode] = true
+			candidates = append(candidates, shortCode)
+		}
+
+		exists, err := s.bloomFilter.MExists(ctx, candidates)
+		if err != nil {
+			s.loggerFor(ctx).Warn("bloom filter check failed", zap.Error(err))
+			return append(codes, candidates...), nil
+		}
+
+		for j, shortCode := range candidates {
+			if exists[j] {
+				s.generationStats.Retries.Add(1)
+				continue
+			}
+			codes = append(codes, shortCode)
+		}
+	}
+
+	if len(codes) < n {
+		s.generationStats.Failures.Add(1)
+		return nil, fmt.Errorf("failed to generate %d unique short codes after %d attempts", n, batchMaxRetries)
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"short-url/internal/cache"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultBloomGrowthFactor = 2.0
+
+// BloomFilterStats 布隆过滤器容量监控数据
+type BloomFilterStats struct {
+	Backend         string     `json:"backend"`
+	Capacity        int64      `json:"capacity"`
+	Items           int64      `json:"items"`
+	FillRatio       float64    `json:"fill_ratio"`
+	EstimatedFPR    float64    `json:"estimated_fpr"`
+	TargetErrorRate float64    `json:"target_error_rate"`
+	RotateThreshold float64    `json:"rotate_threshold"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"`
+	LastRotationAt  *time.Time `json:"last_rotation_at,omitempty"`
+	Rotations       int        `json:"rotations"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// bloomMonitor 保存最近一次容量检查结果
+type bloomMonitor struct {
+	mu    sync.Mutex
+	stats BloomFilterStats
+	// unavailable 最近一次监控检查失败，只由监控和重建更新，管理员查询不会改变它
+	unavailable bool
+}
+
+// BloomFilterStats 读取 BF.INFO 计算当前填充率和估算误判率。
+// 只读，不更新监控结果，也不影响过滤器是否可信
+func (s *ShortLinkService) BloomFilterStats(ctx context.Context) (BloomFilterStats, error) {
+	ctx, span := startSpan(ctx, "BloomFilterStats")
+	defer span.End()
+
+	cfg := s.config.BloomFilter
+	now := time.Now()
+
+	s.bloomMonitor.mu.Lock()
+	stats := s.bloomMonitor.stats
+	s.bloomMonitor.mu.Unlock()
+
+	stats.Backend = s.bloomFilter.Backend()
+	stats.TargetErrorRate = cfg.ErrorRate
+	stats.RotateThreshold = cfg.RotateThreshold
+	stats.CheckedAt = &now
+	stats.Error = ""
+
+	info, err := s.bloomFilter.Info(ctx)
+	if err != nil {
+		stats.Error = err.Error()
+		return stats, err
+	}
+
+	stats.Capacity = cache.InfoInt(info, cache.BloomInfoCapacity)
+	stats.Items = cache.InfoInt(info, cache.BloomInfoItemsInserted)
+	stats.FillRatio, stats.EstimatedFPR = 0, 0
+	if stats.Capacity > 0 {
+		stats.FillRatio = float64(stats.Items) / float64(stats.Capacity)
+		stats.EstimatedFPR = cache.EstimateFalsePositiveRate(int(stats.Capacity), cfg.ErrorRate, stats.Items)
+	}
+
+	return stats, nil
+}
+
+// bloomFilterTrusted 最近一次监控检查发现过滤器不可用（如键被清空）时，
+// 不再用它否定短码存在性，避免把已有短码误报为不存在
+func (s *ShortLinkService) bloomFilterTrusted() bool {
+	s.bloomMonitor.mu.Lock()
+	defer s.bloomMonitor.mu.Unlock()
+	return !s.bloomMonitor.unavailable
+}
+
+// setBloomFilterAvailable 记录监控检查或重建的结果
+func (s *ShortLinkService) setBloomFilterAvailable(available bool) {
+	s.bloomMonitor.mu.Lock()
+	defer s.bloomMonitor.mu.Unlock()
+	s.bloomMonitor.unavailable = !available
+}
+
+// bloomFilterPopulated 过滤器带有完整装载标记时，其"不存在"的判断才可信
+func (s *ShortLinkService) bloomFilterPopulated(ctx context.Context) bool {
+	populated, err := s.bloomFilter.Populated(ctx)
+	if err != nil {
+		s.loggerFor(ctx).Warn("bloom filter populated check failed", zap.Error(err))
+		return false
+	}
+	return populated
+}
+
+// bloomRejectionTrusted 布隆过滤器"不存在"的判断是否可以直接作为结论
+func (s *ShortLinkService) bloomRejectionTrusted(ctx context.Context) bool {
+	return s.bloomFilterTrusted() && s.bloomFilterPopulated(ctx)
+}
+
+// CheckBloomFilter 检查布隆过滤器容量，填充率超过阈值时触发扩容轮换
+func (s *ShortLinkService) CheckBloomFilter(ctx context.Context) (BloomFilterStats, error) {
+	ctx, span := startSpan(ctx, "CheckBloomFilter")
+	defer span.End()
+
+	stats, err := s.BloomFilterStats(ctx)
+	s.setBloomFilterStats(stats)
+	s.setBloomFilterAvailable(err == nil)
+	if err != nil {
+		return stats, err
+	}
+
+	threshold := s.config.BloomFilter.RotateThreshold
+	if threshold <= 0 || stats.FillRatio < threshold {
+		return stats, nil
+	}
+
+	if s.rotateBloomFilter(stats) {
+		s.bloomMonitor.mu.Lock()
+		s.bloomMonitor.stats.LastRotationAt = stats.CheckedAt
+		s.bloomMonitor.stats.Rotations++
+		stats = s.bloomMonitor.stats
+		s.bloomMonitor.mu.Unlock()
+	}
+
+	return stats, nil
+}
+
+// rotateBloomFilter 按增长系数扩容，后台从数据库回填新过滤器后原子替换
+func (s *ShortLinkService) rotateBloomFilter(stats BloomFilterStats) bool {
+	growth := s.config.BloomFilter.GrowthFactor
+	if growth <= 1 {
+		growth = defaultBloomGrowthFactor
+	}
+
+	base := stats.Capacity
+	if stats.Items > base {
+		base = stats.Items
+	}
+	newCapacity := int(float64(base) * growth)
+
+	if _, err := s.startBloomSync(newCapacity); err != nil {
+		if !errors.Is(err, ErrBloomSyncRunning) {
+			s.logger.Warn("failed to start bloom filter rotation", zap.Error(err))
+		}
+		return false
+	}
+
+	s.logger.Info("bloom filter rotation started",
+		zap.Float64("fill_ratio", stats.FillRatio),
+		zap.Float64("estimated_fpr", stats.EstimatedFPR),
+		zap.Int64("capacity", stats.Capacity),
+		zap.Int("new_capacity", newCapacity),
+	)
+	return true
+}
+
+func (s *ShortLinkService) setBloomFilterStats(stats BloomFilterStats) {
+	s.bloomMonitor.mu.Lock()
+	defer s.bloomMonitor.mu.Unlock()
+	s.bloomMonitor.stats = stats
+}
+
+// runBloomMonitor 周期性检查布隆过滤器容量
+func (s *ShortLinkService) runBloomMonitor(interval time.Duration) {
+	defer s.workers.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.CheckBloomFilter(context.Background()); err != nil {
+			s.logger.Warn("bloom filter check failed", zap.Error(err))
+		}
+
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/cache"
+	"testing"
+)
+
+func TestBloomFilterTrust(t *testing.T) {
+	// 各步骤依次执行，过滤器初始未创建，BF.INFO 会失败
+	const (
+		adminRead = "admin read"
+		monitor   = "monitor check"
+		create    = "create filter"
+		rebuild   = "rebuild"
+	)
+
+	tests := []struct {
+		name  string
+		steps []string
+		want  bool
+	}{
+		{name: "trusted until a check fails", steps: nil, want: true},
+		{name: "failed admin read keeps trust", steps: []string{adminRead, adminRead}, want: true},
+		{name: "failed monitor check drops trust", steps: []string{monitor}, want: false},
+		{name: "admin read does not restore trust", steps: []string{monitor, create, adminRead}, want: false},
+		{name: "monitor check restores trust", steps: []string{monitor, create, monitor}, want: true},
+		{name: "rebuild restores trust", steps: []string{monitor, rebuild}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, _, _ := newTestService(t)
+			bf := cache.NewBloomFilter(nil, &svc.config.BloomFilter)
+			svc.bloomFilter = bf
+
+			for _, step := range tt.steps {
+				switch step {
+				case adminRead:
+					svc.BloomFilterStats(ctx)
+				case monitor:
+					svc.CheckBloomFilter(ctx)
+				case create:
+					if err := bf.Initialize(ctx); err != nil {
+						t.Fatalf("Initialize() error = %v", err)
+					}
+				case rebuild:
+					if _, err := svc.startBloomSync(0); err != nil {
+						t.Fatalf("startBloomSync() error = %v", err)
+					}
+					svc.workers.Wait()
+					if status := svc.BloomFilterRebuildStatus(); status.Error != "" {
+						t.Fatalf("rebuild error = %s", status.Error)
+					}
+				}
+			}
+
+			if got := svc.bloomFilterTrusted(); got != tt.want {
+				t.Errorf("bloomFilterTrusted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBloomFilterStatsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t)
+
+	if _, err := svc.CheckBloomFilter(ctx); err != nil {
+		t.Fatalf("CheckBloomFilter() error = %v", err)
+	}
+	checked := svc.bloomMonitor.stats.CheckedAt
+
+	svc.bloomFilter = cache.NewBloomFilter(nil, &svc.config.BloomFilter)
+	stats, err := svc.BloomFilterStats(ctx)
+	if err == nil || stats.Error == "" {
+		t.Fatalf("BloomFilterStats() = %+v, %v; want error", stats, err)
+	}
+	if svc.bloomMonitor.stats.Error != "" || svc.bloomMonitor.stats.CheckedAt != checked {
+		t.Errorf("admin read changed the monitor result: %+v", svc.bloomMonitor.stats)
+	}
+}
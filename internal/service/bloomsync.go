@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/cache"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBloomSyncBatchSize     = 5000
+	defaultBloomSyncCatchUpWindow = 5 * time.Minute
+)
+
+// BloomSyncOptions 布隆过滤器重建参数
+type BloomSyncOptions struct {
+	// BatchSize 每批从数据库读取的短码数量
+	BatchSize int
+	// Capacity 新过滤器容量，为 0 时沿用当前过滤器容量，且不小于配置容量
+	Capacity int
+	// CatchUpWindow 补扫向扫描开始前回溯的时长，需覆盖最长的创建事务和应用与数据库的时钟偏差
+	CatchUpWindow time.Duration
+	// Progress 每处理完一批后回调，参数为累计处理的短码数
+	Progress func(scanned int64)
+}
+
+// BloomSyncResult 布隆过滤器重建结果
+type BloomSyncResult struct {
+	Scanned    int64                  `json:"scanned"`
+	CaughtUp   int64                  `json:"caught_up"`
+	Info       map[string]interface{} `json:"info,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt time.Time              `json:"finished_at"`
+}
+
+// RebuildBloomFilter 从存储中流式读取所有短码，在临时键上重建布隆过滤器后原子替换。
+// 重建期间本进程新增的短码同时写入临时过滤器；替换前后各补扫一次扫描开始前 CatchUpWindow 以来创建的短码，
+// 覆盖扫描期间提交以及其他实例只写入当前过滤器的短码，全部完成后标记过滤器已完整装载。
+// 补扫按创建时间而不是 ID：ID 在插入时分配，ID 较小但提交较晚的短码会被按 ID 的扫描越过。
+func RebuildBloomFilter(ctx context.Context, store ShortLinkStore, bloomFilter *cache.BloomFilter, opts BloomSyncOptions) (*BloomSyncResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBloomSyncBatchSize
+	}
+	if opts.CatchUpWindow <= 0 {
+		opts.CatchUpWindow = defaultBloomSyncCatchUpWindow
+	}
+
+	unlock, err := bloomFilter.Lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// 未指定容量时不小于当前过滤器容量，避免轮换扩容后被重建缩小
+	if opts.Capacity <= 0 {
+		if info, err := bloomFilter.Info(ctx); err == nil {
+			opts.Capacity = int(cache.InfoInt(info, cache.BloomInfoCapacity))
+		}
+	}
+
+	result := &BloomSyncResult{StartedAt: time.Now()}
+
+	staging := bloomFilter.Staging(opts.Capacity)
+	if err := staging.Reset(ctx); err != nil {
+		return nil, fmt.Errorf("failed to prepare staging bloom filter: %w", err)
+	}
+
+	stopMirror := bloomFilter.Mirror(staging)
+	defer stopMirror()
+
+	// created_at 取创建事务的开始时间，扫描开始之后才提交的短码都不早于 since
+	since := result.StartedAt.Add(-opts.CatchUpWindow)
+
+	scanned, err := addShortCodes(ctx, store, staging, time.Time{}, opts.BatchSize, opts.Progress)
+	result.Scanned = scanned
+	if err != nil {
+		return result, err
+	}
+
+	// 替换前补扫扫描期间新增的短码，替换后的过滤器不会缺少它们
+	caughtUp, err := addShortCodes(ctx, store, staging, since, opts.BatchSize, nil)
+	result.CaughtUp = caughtUp
+	if err != nil {
+		return result, fmt.Errorf("failed to catch up staging bloom filter: %w", err)
+	}
+
+	// 替换之后临时键不复存在，先停止双写，避免写入重新创建临时键
+	stopMirror()
+	if err := bloomFilter.Promote(ctx, staging); err != nil {
+		return result, err
+	}
+
+	// 再补扫一次，覆盖停止双写之后以及其他实例只写入旧过滤器的短码
+	caughtUp, err = addShortCodes(ctx, store, bloomFilter, since, opts.BatchSize, nil)
+	result.CaughtUp += caughtUp
+	if err != nil {
+		return result, fmt.Errorf("failed to catch up bloom filter: %w", err)
+	}
+
+	if err := bloomFilter.MarkPopulated(ctx); err != nil {
+		return result, fmt.Errorf("failed to mark bloom filter populated: %w", err)
+	}
+
+	info, err := bloomFilter.Info(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to get bloom filter info: %w", err)
+	}
+
+	result.Info = info
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+// addShortCodes 将 since 及之后创建的所有短码分批加入布隆过滤器，since 为零值时加入全部短码
+func addShortCodes(ctx context.Context, store ShortLinkStore, bloomFilter *cache.BloomFilter, since time.Time, batchSize int, progress func(int64)) (int64, error) {
+	var afterID, scanned int64
+	for {
+		codes, lastID, err := store.ScanShortCodes(ctx, since, afterID, batchSize)
+		if err != nil {
+			return scanned, err
+		}
+		if len(codes) == 0 {
+			return scanned, nil
+		}
+
+		if _, err := bloomFilter.MAdd(ctx, codes); err != nil {
+			return scanned, fmt.Errorf("failed to add short codes to bloom filter: %w", err)
+		}
+
+		afterID = lastID
+		scanned += int64(len(codes))
+		if progress != nil {
+			progress(scanned)
+		}
+	}
+}
+
+// prepareBloomFilter 启动时确认过滤器已完整装载。内存过滤器随进程重启而清空，
+// 直接从存储中重新装载；Redis 过滤器未装载时只提示运维执行重建，期间短码存在性交给数据库判断
+func (s *ShortLinkService) prepareBloomFilter(ctx context.Context) {
+	if s.bloomFilter.Backend() == cache.BloomBackendMemory {
+		result, err := RebuildBloomFilter(ctx, s.repo, s.bloomFilter, BloomSyncOptions{
+			BatchSize: s.config.BloomFilter.SyncBatchSize,
+		})
+		if err != nil {
+			s.logger.Error("failed to load memory bloom filter", zap.Error(err))
+			return
+		}
+		s.setBloomFilterAvailable(true)
+		s.logger.Info("memory bloom filter loaded", zap.Int64("scanned", result.Scanned))
+		return
+	}
+
+	populated, err := s.bloomFilter.Populated(ctx)
+	if err != nil {
+		s.logger.Warn("failed to check bloom filter", zap.Error(err))
+		return
+	}
+	if !populated {
+		s.logger.Warn("bloom filter is not populated, run bloomsync or POST /api/v1/admin/bloom/rebuild to enable bloom rejections")
+	}
+}
+
+// BloomSyncStatus 后台重建任务状态
+type BloomSyncStatus struct {
+	Running    bool             `json:"running"`
+	Scanned    int64            `json:"scanned"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	Result     *BloomSyncResult `json:"result,omitempty"`
+}
+
+// bloomSyncJob 记录进程内唯一的重建任务
+type bloomSyncJob struct {
+	mu     sync.Mutex
+	status BloomSyncStatus
+}
+
+// StartBloomFilterRebuild 在后台启动布隆过滤器重建
+func (s *ShortLinkService) StartBloomFilterRebuild() (BloomSyncStatus, error) {
+	return s.startBloomSync(0)
+}
+
+// startBloomSync 在后台以指定容量重建布隆过滤器，capacity 为 0 时沿用当前容量
+func (s *ShortLinkService) startBloomSync(capacity int) (BloomSyncStatus, error) {
+	s.bloomSync.mu.Lock()
+	defer s.bloomSync.mu.Unlock()
+
+	if s.bloomSync.status.Running {
+		return s.bloomSync.status, ErrBloomSyncRunning
+	}
+
+	startedAt := time.Now()
+	s.bloomSync.status = BloomSyncStatus{Running: true, StartedAt: &startedAt}
+
+	s.workers.Add(1)
+	go s.runBloomFilterRebuild(capacity)
+
+	return s.bloomSync.status, nil
+}
+
+// BloomFilterRebuildStatus 获取最近一次重建任务的状态
+func (s *ShortLinkService) BloomFilterRebuildStatus() BloomSyncStatus {
+	s.bloomSync.mu.Lock()
+	defer s.bloomSync.mu.Unlock()
+	return s.bloomSync.status
+}
+
+func (s *ShortLinkService) runBloomFilterRebuild(capacity int) {
+	defer s.workers.Done()
+
+	// 服务停止时中断重建，未完成的临时过滤器不会替换当前过滤器
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	opts := BloomSyncOptions{
+		BatchSize: s.config.BloomFilter.SyncBatchSize,
+		Capacity:  capacity,
+		Progress: func(scanned int64) {
+			s.bloomSync.mu.Lock()
+			s.bloomSync.status.Scanned = scanned
+			s.bloomSync.mu.Unlock()
+		},
+	}
+
+	result, err := RebuildBloomFilter(ctx, s.repo, s.bloomFilter, opts)
+
+	s.bloomSync.mu.Lock()
+	defer s.bloomSync.mu.Unlock()
+
+	finishedAt := time.Now()
+	s.bloomSync.status.Running = false
+	s.bloomSync.status.FinishedAt = &finishedAt
+	s.bloomSync.status.Result = result
+	if err != nil {
+		s.bloomSync.status.Error = err.Error()
+		s.logger.Error("bloom filter rebuild failed", zap.Error(err))
+		return
+	}
+
+	// 重建成功说明过滤器可用，不必等下一次监控检查
+	s.setBloomFilterAvailable(true)
+	s.logger.Info("bloom filter rebuilt",
+		zap.Int64("scanned", result.Scanned),
+		zap.Int64("caught_up", result.CaughtUp),
+		zap.Duration("duration", result.FinishedAt.Sub(result.StartedAt)),
+	)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"testing"
+	"time"
+)
+
+// lateCommitStore 在全量扫描中隐藏指定短码，模拟 ID 较小但在扫描越过后才提交的短链接
+type lateCommitStore struct {
+	*MemoryRepository
+	hidden string
+}
+
+func (s lateCommitStore) ScanShortCodes(ctx context.Context, since time.Time, afterID int64, limit int) ([]string, int64, error) {
+	codes, lastID, err := s.MemoryRepository.ScanShortCodes(ctx, since, afterID, limit)
+	if err != nil || !since.IsZero() {
+		return codes, lastID, err
+	}
+
+	visible := codes[:0]
+	for _, code := range codes {
+		if code != s.hidden {
+			visible = append(visible, code)
+		}
+	}
+	return visible, lastID, nil
+}
+
+func TestRebuildBloomFilterCatchUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		hidden string
+	}{
+		{name: "all rows committed before the scan"},
+		{name: "row with a lower id committed late", hidden: "late01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo, bf := newTestService(t)
+			codes := []string{"early1", "late01", "after1"}
+			for _, code := range codes {
+				if err := repo.CreateShortLink(ctx, &models.ShortLink{ShortCode: code, OriginalURL: "https://example.com/" + code}); err != nil {
+					t.Fatalf("CreateShortLink() error = %v", err)
+				}
+			}
+
+			store := lateCommitStore{MemoryRepository: repo, hidden: tt.hidden}
+			result, err := RebuildBloomFilter(ctx, store, bf, BloomSyncOptions{BatchSize: 1})
+			if err != nil {
+				t.Fatalf("RebuildBloomFilter() error = %v", err)
+			}
+
+			for _, code := range codes {
+				if exists, err := bf.Exists(ctx, code); err != nil || !exists {
+					t.Errorf("Exists(%q) = %v, %v; want true", code, exists, err)
+				}
+			}
+			if populated, err := bf.Populated(ctx); err != nil || !populated {
+				t.Errorf("Populated() = %v, %v; want true", populated, err)
+			}
+			if !svc.bloomRejectionTrusted(ctx) {
+				t.Errorf("bloomRejectionTrusted() = false after rebuild")
+			}
+			if result.Scanned+result.CaughtUp < int64(len(codes)) {
+				t.Errorf("Scanned, CaughtUp = %d, %d; want at least %d codes in total", result.Scanned, result.CaughtUp, len(codes))
+			}
+		})
+	}
+}
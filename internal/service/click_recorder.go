@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"short-url/internal/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultClickBufferSize    = 10000
+	defaultClickBatchSize     = 500
+	defaultClickFlushInterval = 2 * time.Second
+	clickWriteTimeout         = 10 * time.Second
+
+	// maxClickFieldLength 请求头字段的最大保留长度，避免超长头部占用缓冲区
+	maxClickFieldLength = 1024
+)
+
+// RecordClick 记录一次点击事件。事件进入有界缓冲区后由后台任务批量写入，
+// 缓冲区满时直接丢弃，不阻塞重定向
+func (s *ShortLinkService) RecordClick(event *models.ClickEvent) {
+	if s.clicks == nil {
+		return
+	}
+
+	if event.ClickedAt.IsZero() {
+		event.ClickedAt = time.Now().UTC()
+	}
+	event.Referrer = truncateField(event.Referrer)
+	event.UserAgent = truncateField(event.UserAgent)
+	event.AcceptLanguage = truncateField(event.AcceptLanguage)
+	event.ReferrerDomain = utils.ReferrerDomain(event.Referrer)
+	event.DeviceClass = utils.DeviceClass(event.UserAgent)
+	// 指纹在匿名化之前计算，避免同一网段的访客被合并
+	event.VisitorID = utils.VisitorFingerprint(event.ClientIP, event.UserAgent)
+	if s.config.Analytics.AnonymizeIP {
+		event.ClientIP = utils.AnonymizeIP(event.ClientIP)
+	}
+
+	select {
+	case s.clicks <- event:
+	default:
+		s.redirectStats.ClicksDropped.Add(1)
+	}
+}
+
+// runClickRecorder 从缓冲区读取点击事件，攒满一批或到达间隔时写入存储；停止时写完缓冲区中剩余的事件
+func (s *ShortLinkService) runClickRecorder(interval time.Duration, batchSize int) {
+	defer s.workers.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]*models.ClickEvent, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.writeClickEvents(batch)
+		batch = make([]*models.ClickEvent, 0, batchSize)
+	}
+
+	for {
+		select {
+		case event := <-s.clicks:
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.stopCh:
+			for {
+				select {
+				case event := <-s.clicks:
+					batch = append(batch, event)
+					if len(batch) >= batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *ShortLinkService) writeClickEvents(events []*models.ClickEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), clickWriteTimeout)
+	defer cancel()
+
+	if s.visitorCounter != nil {
+		visits := make([]Visit, len(events))
+		for i, event := range events {
+			visits[i] = Visit{ShortCode: event.ShortCode, VisitorID: event.VisitorID, At: event.ClickedAt}
+		}
+		if err := s.visitorCounter.Add(ctx, visits); err != nil {
+			s.logger.Error("failed to count unique visitors", zap.Int("events", len(events)), zap.Error(err))
+		}
+	}
+
+	if err := s.repo.InsertClickEvents(ctx, events); err != nil {
+		s.redirectStats.ClicksDropped.Add(int64(len(events)))
+		s.logger.Error("failed to write click events, events lost", zap.Int("events", len(events)), zap.Error(err))
+		return
+	}
+	s.redirectStats.ClicksRecorded.Add(int64(len(events)))
+}
+
+// truncateField 替换无效的 UTF-8 序列并在字符边界截断，
+// Postgres 拒绝无效编码的文本，一个异常请求头会让整批 COPY 失败
+func truncateField(value string) string {
+	value = strings.ToValidUTF8(value, "\uFFFD")
+	if len(value) <= maxClickFieldLength {
+		return value
+	}
+
+	cut := maxClickFieldLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
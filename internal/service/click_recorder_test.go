@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"short-url/internal/utils"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestRecordClick(t *testing.T) {
+	tests := []struct {
+		name       string
+		anonymize  bool
+		event      models.ClickEvent
+		wantIP     string
+		wantDomain string
+		wantDevice string
+		wantUALen  int
+	}{
+		{
+			name:       "derived fields",
+			event:      models.ClickEvent{ClientIP: "203.0.113.7", Referrer: "https://News.Example.com/a?b=c", UserAgent: "Mozilla/5.0 (iPhone; Mobile)"},
+			wantIP:     "203.0.113.7",
+			wantDomain: "news.example.com",
+			wantDevice: utils.DeviceClass("Mozilla/5.0 (iPhone; Mobile)"),
+			wantUALen:  len("Mozilla/5.0 (iPhone; Mobile)"),
+		},
+		{
+			name:       "anonymized IP",
+			anonymize:  true,
+			event:      models.ClickEvent{ClientIP: "203.0.113.7", UserAgent: "curl/8.0"},
+			wantIP:     "203.0.113.0",
+			wantDevice: utils.DeviceBot,
+			wantUALen:  len("curl/8.0"),
+		},
+		{
+			// 截断落在多字节字符中间时退回到字符边界
+			name:       "oversized header truncated on a rune boundary",
+			event:      models.ClickEvent{UserAgent: "a" + strings.Repeat("é", maxClickFieldLength)},
+			wantDevice: utils.DeviceClass("a" + strings.Repeat("é", maxClickFieldLength)),
+			wantUALen:  maxClickFieldLength - 1,
+		},
+		{
+			name:       "invalid UTF-8 replaced",
+			event:      models.ClickEvent{UserAgent: "bad\xffagent"},
+			wantDevice: utils.DeviceClass("bad�agent"),
+			wantUALen:  len("bad�agent"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newTestService(t)
+			svc.config.Analytics.AnonymizeIP = tt.anonymize
+			svc.clicks = make(chan *models.ClickEvent, 1)
+
+			event := tt.event
+			svc.RecordClick(&event)
+			got := <-svc.clicks
+
+			if got.ClickedAt.IsZero() {
+				t.Error("ClickedAt not set")
+			}
+			if got.ClientIP != tt.wantIP {
+				t.Errorf("ClientIP = %q, want %q", got.ClientIP, tt.wantIP)
+			}
+			if got.ReferrerDomain != tt.wantDomain {
+				t.Errorf("ReferrerDomain = %q, want %q", got.ReferrerDomain, tt.wantDomain)
+			}
+			if got.DeviceClass != tt.wantDevice {
+				t.Errorf("DeviceClass = %q, want %q", got.DeviceClass, tt.wantDevice)
+			}
+			if len(got.UserAgent) != tt.wantUALen || !utf8.ValidString(got.UserAgent) {
+				t.Errorf("UserAgent length = %d, valid UTF-8 = %v; want %d, true", len(got.UserAgent), utf8.ValidString(got.UserAgent), tt.wantUALen)
+			}
+			// 指纹在匿名化之前计算
+			if want := utils.VisitorFingerprint(tt.event.ClientIP, got.UserAgent); got.VisitorID != want {
+				t.Errorf("VisitorID = %q, want %q", got.VisitorID, want)
+			}
+		})
+	}
+}
+
+func TestRecordClickDropsWhenBufferFull(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	svc.clicks = make(chan *models.ClickEvent, 2)
+
+	for i := 0; i < 5; i++ {
+		svc.RecordClick(&models.ClickEvent{ShortCode: "code01"})
+	}
+
+	if got := len(svc.clicks); got != 2 {
+		t.Errorf("buffered events = %d, want 2", got)
+	}
+	if got := svc.redirectStats.ClicksDropped.Load(); got != 3 {
+		t.Errorf("ClicksDropped = %d, want 3", got)
+	}
+}
+
+func TestClickRecorderFlushesOnShutdown(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestService(t)
+	if err := repo.CreateShortLink(ctx, &models.ShortLink{ShortCode: "code01", OriginalURL: "https://example.com"}); err != nil {
+		t.Fatalf("CreateShortLink() error = %v", err)
+	}
+	svc.clicks = make(chan *models.ClickEvent, 10)
+
+	// 间隔和批量都不会触发写入，事件只能在停止时写出
+	svc.workers.Add(1)
+	go svc.runClickRecorder(time.Hour, 100)
+
+	svc.RecordClick(&models.ClickEvent{ShortCode: "code01", Referrer: "https://example.org/post", UserAgent: "Mozilla/5.0 (Windows NT 10.0)"})
+	svc.RecordClick(&models.ClickEvent{ShortCode: "code01", UserAgent: "Mozilla/5.0 (Windows NT 10.0)"})
+	svc.RecordClick(&models.ClickEvent{ShortCode: "code01", UserAgent: "Googlebot/2.1"})
+
+	if err := svc.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := svc.redirectStats.ClicksRecorded.Load(); got != 3 {
+		t.Errorf("ClicksRecorded = %d, want 3", got)
+	}
+
+	analytics, err := svc.GetLinkAnalytics(ctx, "code01", &models.LinkAnalyticsRequest{})
+	if err != nil {
+		t.Fatalf("GetLinkAnalytics() error = %v", err)
+	}
+	if analytics.TotalClicks != 3 {
+		t.Errorf("TotalClicks = %d, want 3", analytics.TotalClicks)
+	}
+
+	referrers := make(map[string]int64)
+	for _, referrer := range analytics.Referrers {
+		referrers[referrer.Key] = referrer.Clicks
+	}
+	if referrers[directReferrer] != 2 || referrers["example.org"] != 1 {
+		t.Errorf("Referrers = %+v, want 2 direct and 1 example.org", analytics.Referrers)
+	}
+}
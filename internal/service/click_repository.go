@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"short-url/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// InsertClickEvents 通过 COPY 批量写入点击事件
+func (r *Repository) InsertClickEvents(ctx context.Context, events []*models.ClickEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(events))
+	for i, event := range events {
+		rows[i] = []interface{}{
+			event.ShortCode,
+			event.ClickedAt,
+			nullString(event.Referrer),
+			nullString(event.ReferrerDomain),
+			nullString(event.UserAgent),
+			event.DeviceClass,
+			nullString(event.ClientIP),
+			nullString(event.AcceptLanguage),
+		}
+	}
+
+	columns := []string{
+		"short_code", "clicked_at", "referrer", "referrer_domain",
+		"user_agent", "device_class", "client_ip", "accept_language",
+	}
+	if _, err := r.db.Pool.CopyFrom(ctx, pgx.Identifier{"click_events"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("failed to insert click events: %w", err)
+	}
+
+	return nil
+}
+
+// clickRollupName analytics_rollup_state 中点击事件汇总进度的名称，同时用作汇总的 advisory lock 键
+const clickRollupName = "click_events"
+
+// GetClickAnalytics 统计时间范围内的点击序列和来源、设备分布。
+// 汇总进度之前的部分读取汇总表：按天分桶时整天的部分读取天汇总表，其余读取小时汇总表；之后的部分读取原始点击事件
+func (r *Repository) GetClickAnalytics(ctx context.Context, query AnalyticsQuery) (*models.LinkAnalytics, error) {
+	watermark, err := r.rollupWatermark(ctx, clickRollupName)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newClickStats(query.Interval)
+
+	rollupFrom, rollupTo := rollupReadRange(query, watermark)
+	for _, segment := range rollupSegments(rollupFrom, rollupTo, query.Interval) {
+		if err := r.readClickRollup(ctx, segment.table, query, segment.from, segment.to, stats); err != nil {
+			return nil, err
+		}
+	}
+
+	rawFrom := query.From
+	if rawFrom.Before(watermark) {
+		rawFrom = watermark
+	}
+	if rawFrom.Before(query.To) {
+		if err := r.readClickEvents(ctx, query, rawFrom, stats); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats.analytics(query), nil
+}
+
+// rollupSegment 从某张汇总表读取的时间范围
+type rollupSegment struct {
+	table string
+	from  time.Time
+	to    time.Time
+}
+
+// rollupSegments 拆分汇总表的读取范围，按天分桶时中间的整天读取天汇总表
+func rollupSegments(from, to time.Time, interval string) []rollupSegment {
+	if !from.Before(to) {
+		return nil
+	}
+
+	dayStart := truncateToInterval(from, AnalyticsIntervalDay)
+	if dayStart.Before(from) {
+		dayStart = dayStart.AddDate(0, 0, 1)
+	}
+	dayEnd := truncateToInterval(to, AnalyticsIntervalDay)
+	if interval != AnalyticsIntervalDay || !dayStart.Before(dayEnd) {
+		return []rollupSegment{{table: "link_stats_hourly", from: from, to: to}}
+	}
+
+	segments := []rollupSegment{{table: "link_stats_daily", from: dayStart, to: dayEnd}}
+	if from.Before(dayStart) {
+		segments = append(segments, rollupSegment{table: "link_stats_hourly", from: from, to: dayStart})
+	}
+	if dayEnd.Before(to) {
+		segments = append(segments, rollupSegment{table: "link_stats_hourly", from: dayEnd, to: to})
+	}
+	return segments
+}
+
+// readClickRollup 读取汇总表中 [from, to) 的记录
+func (r *Repository) readClickRollup(ctx context.Context, table string, query AnalyticsQuery, from, to time.Time, stats *clickStats) error {
+	sql := `
+		SELECT bucket, dimension, key, SUM(clicks)
+		FROM ` + table + `
+		WHERE short_code = $1 AND bucket >= $2 AND bucket < $3
+		GROUP BY bucket, dimension, key
+	`
+
+	rows, err := r.db.Pool.Query(ctx, sql, query.ShortCode, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get click rollup: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bucket         time.Time
+			dimension, key string
+			clicks         int64
+		)
+		if err := rows.Scan(&bucket, &dimension, &key, &clicks); err != nil {
+			return fmt.Errorf("failed to scan click rollup: %w", err)
+		}
+		stats.addRollup(bucket, dimension, key, clicks)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// readClickEvents 读取 from 到查询终点之间的原始点击事件，按分桶、来源和设备聚合
+func (r *Repository) readClickEvents(ctx context.Context, query AnalyticsQuery, from time.Time, stats *clickStats) error {
+	sql := `
+		SELECT date_trunc($4, clicked_at, 'UTC') AS bucket, COALESCE(referrer_domain, ''), device_class, COUNT(*)
+		FROM click_events
+		WHERE short_code = $1 AND clicked_at >= $2 AND clicked_at < $3
+		GROUP BY 1, 2, 3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, sql, query.ShortCode, from, query.To, query.Interval)
+	if err != nil {
+		return fmt.Errorf("failed to get click events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bucket                      time.Time
+			referrerDomain, deviceClass string
+			clicks                      int64
+		)
+		if err := rows.Scan(&bucket, &referrerDomain, &deviceClass, &clicks); err != nil {
+			return fmt.Errorf("failed to scan click events: %w", err)
+		}
+		stats.addClicks(bucket, referrerDomain, deviceClass, clicks)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// RollupClickStats 在同一事务中重算 [watermark, end) 的小时汇总和所涉及日期的天汇总，并推进汇总进度。
+// 重算而不是累加，重复执行结果一致；多实例通过 advisory lock 保证同一时刻只有一个实例汇总
+func (r *Repository) RollupClickStats(ctx context.Context, until time.Time) (time.Time, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, clickRollupName).Scan(&locked); err != nil {
+		return time.Time{}, fmt.Errorf("failed to acquire click rollup lock: %w", err)
+	}
+	if !locked {
+		return time.Time{}, nil
+	}
+
+	var start time.Time
+	err = tx.QueryRow(ctx, `SELECT watermark FROM analytics_rollup_state WHERE name = $1 FOR UPDATE`, clickRollupName).Scan(&start)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// 首次汇总从最早的点击事件开始
+		var earliest *time.Time
+		if err := tx.QueryRow(ctx, `SELECT MIN(clicked_at) FROM click_events`).Scan(&earliest); err != nil {
+			return time.Time{}, fmt.Errorf("failed to get earliest click event: %w", err)
+		}
+		start = until
+		if earliest != nil && earliest.Before(until) {
+			start = earliest.UTC().Truncate(time.Hour)
+		}
+	case err != nil:
+		return time.Time{}, fmt.Errorf("failed to get click rollup watermark: %w", err)
+	}
+	start = start.UTC()
+
+	end := until
+	if end.Sub(start) > maxClickRollupSpan {
+		end = start.Add(maxClickRollupSpan)
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	if start.Before(end) {
+		if _, err := tx.Exec(ctx, `DELETE FROM link_stats_hourly WHERE bucket >= $1 AND bucket < $2`, start, end); err != nil {
+			return time.Time{}, fmt.Errorf("failed to clear hourly click stats: %w", err)
+		}
+
+		hourlyQuery := `
+			INSERT INTO link_stats_hourly (short_code, bucket, dimension, key, clicks)
+			SELECT short_code, date_trunc('hour', clicked_at, 'UTC'), $3, '', COUNT(*)
+			FROM click_events WHERE clicked_at >= $1 AND clicked_at < $2
+			GROUP BY 1, 2
+			UNION ALL
+			SELECT short_code, date_trunc('hour', clicked_at, 'UTC'), $4, COALESCE(referrer_domain, ''), COUNT(*)
+			FROM click_events WHERE clicked_at >= $1 AND clicked_at < $2
+			GROUP BY 1, 2, 4
+			UNION ALL
+			SELECT short_code, date_trunc('hour', clicked_at, 'UTC'), $5, device_class, COUNT(*)
+			FROM click_events WHERE clicked_at >= $1 AND clicked_at < $2
+			GROUP BY 1, 2, 4
+		`
+		if _, err := tx.Exec(ctx, hourlyQuery, start, end,
+			statsDimensionTotal, statsDimensionReferrer, statsDimensionDevice); err != nil {
+			return time.Time{}, fmt.Errorf("failed to roll up hourly click stats: %w", err)
+		}
+
+		// 天汇总由所涉及日期的小时汇总重算
+		dayStart := truncateToInterval(start, AnalyticsIntervalDay)
+		if _, err := tx.Exec(ctx, `DELETE FROM link_stats_daily WHERE bucket >= $1 AND bucket < $2`, dayStart, end); err != nil {
+			return time.Time{}, fmt.Errorf("failed to clear daily click stats: %w", err)
+		}
+
+		dailyQuery := `
+			INSERT INTO link_stats_daily (short_code, bucket, dimension, key, clicks)
+			SELECT short_code, date_trunc('day', bucket, 'UTC'), dimension, key, SUM(clicks)
+			FROM link_stats_hourly
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY 1, 2, 3, 4
+		`
+		if _, err := tx.Exec(ctx, dailyQuery, dayStart, end); err != nil {
+			return time.Time{}, fmt.Errorf("failed to roll up daily click stats: %w", err)
+		}
+	}
+
+	stateQuery := `
+		INSERT INTO analytics_rollup_state (name, watermark)
+		VALUES ($1, $2)
+		ON CONFLICT (name)
+		DO UPDATE SET watermark = EXCLUDED.watermark, updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := tx.Exec(ctx, stateQuery, clickRollupName, end); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save click rollup watermark: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit click rollup: %w", err)
+	}
+
+	return end, nil
+}
+
+// PruneClickEvents 分批删除 before 之前的原始点击事件，避免长时间持有大量行锁
+func (r *Repository) PruneClickEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM click_events
+		WHERE id IN (
+			SELECT id FROM click_events
+			WHERE clicked_at < $1
+			ORDER BY clicked_at
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune click events: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// nullString 空字符串写入为 NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// truncateToInterval 将时间截断到分桶起点（UTC），与 date_trunc 的结果一致
+func truncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	if interval == AnalyticsIntervalDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// SaveUniqueVisitors 在同一事务中写入按天的独立访客数和全部时间的独立访客数
+func (r *Repository) SaveUniqueVisitors(ctx context.Context, daily []DailyVisitors, totals map[string]int64) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if len(daily) > 0 {
+		codes := make([]string, len(daily))
+		days := make([]time.Time, len(daily))
+		visitors := make([]int64, len(daily))
+		for i, d := range daily {
+			codes[i] = d.ShortCode
+			days[i] = d.Day
+			visitors[i] = d.Visitors
+		}
+
+		query := `
+			INSERT INTO link_unique_visitors (short_code, day, visitors)
+			SELECT * FROM unnest($1::text[], $2::date[], $3::bigint[])
+			ON CONFLICT (short_code, day)
+			DO UPDATE SET visitors = EXCLUDED.visitors, updated_at = CURRENT_TIMESTAMP
+		`
+		if _, err := tx.Exec(ctx, query, codes, days, visitors); err != nil {
+			return fmt.Errorf("failed to save daily unique visitors: %w", err)
+		}
+	}
+
+	if len(totals) > 0 {
+		codes := make([]string, 0, len(totals))
+		visitors := make([]int64, 0, len(totals))
+		for shortCode, total := range totals {
+			codes = append(codes, shortCode)
+			visitors = append(visitors, total)
+		}
+
+		query := `
+			UPDATE short_links AS s
+			SET unique_visitors = t.visitors
+			FROM unnest($1::text[], $2::bigint[]) AS t(short_code, visitors)
+			WHERE s.short_code = t.short_code
+		`
+		if _, err := tx.Exec(ctx, query, codes, visitors); err != nil {
+			return fmt.Errorf("failed to save unique visitors: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit unique visitors: %w", err)
+	}
+
+	return nil
+}
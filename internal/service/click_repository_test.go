@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupSegments(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		interval string
+		want     []rollupSegment
+	}{
+		{
+			name: "empty range", from: day(1, 0), to: day(1, 0), interval: AnalyticsIntervalDay,
+			want: nil,
+		},
+		{
+			name: "reversed range", from: day(2, 0), to: day(1, 0), interval: AnalyticsIntervalDay,
+			want: nil,
+		},
+		{
+			name: "hourly interval reads hourly table", from: day(1, 0), to: day(5, 0), interval: AnalyticsIntervalHour,
+			want: []rollupSegment{{table: "link_stats_hourly", from: day(1, 0), to: day(5, 0)}},
+		},
+		{
+			name: "within one day", from: day(1, 3), to: day(1, 20), interval: AnalyticsIntervalDay,
+			want: []rollupSegment{{table: "link_stats_hourly", from: day(1, 3), to: day(1, 20)}},
+		},
+		{
+			name: "across midnight without a whole day", from: day(1, 20), to: day(2, 4), interval: AnalyticsIntervalDay,
+			want: []rollupSegment{{table: "link_stats_hourly", from: day(1, 20), to: day(2, 4)}},
+		},
+		{
+			name: "whole days", from: day(1, 0), to: day(4, 0), interval: AnalyticsIntervalDay,
+			want: []rollupSegment{{table: "link_stats_daily", from: day(1, 0), to: day(4, 0)}},
+		},
+		{
+			name: "partial first and last day", from: day(1, 6), to: day(4, 12), interval: AnalyticsIntervalDay,
+			want: []rollupSegment{
+				{table: "link_stats_daily", from: day(2, 0), to: day(4, 0)},
+				{table: "link_stats_hourly", from: day(1, 6), to: day(2, 0)},
+				{table: "link_stats_hourly", from: day(4, 0), to: day(4, 12)},
+			},
+		},
+		{
+			name: "partial last day only", from: day(1, 0), to: day(3, 1), interval: AnalyticsIntervalDay,
+			want: []rollupSegment{
+				{table: "link_stats_daily", from: day(1, 0), to: day(3, 0)},
+				{table: "link_stats_hourly", from: day(3, 0), to: day(3, 1)},
+			},
+		},
+		{
+			// 天边界按 UTC 计算，与汇总表的分桶一致
+			name: "non-UTC input", from: day(1, 0).In(time.FixedZone("UTC+8", 8*3600)), to: day(3, 0), interval: AnalyticsIntervalDay,
+			want: []rollupSegment{{table: "link_stats_daily", from: day(1, 0), to: day(3, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rollupSegments(tt.from, tt.to, tt.interval)
+			if len(got) != len(tt.want) {
+				t.Fatalf("rollupSegments() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].table != tt.want[i].table || !got[i].from.Equal(tt.want[i].from) || !got[i].to.Equal(tt.want[i].to) {
+					t.Errorf("segment[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateToInterval(t *testing.T) {
+	at := time.Date(2024, 3, 1, 23, 45, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		interval string
+		want     time.Time
+	}{
+		{name: "hour", t: at, interval: AnalyticsIntervalHour, want: time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)},
+		{name: "day", t: at, interval: AnalyticsIntervalDay, want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "day in other zone uses UTC date", t: at.In(time.FixedZone("UTC+8", 8*3600)), interval: AnalyticsIntervalDay, want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateToInterval(tt.t, tt.interval); !got.Equal(tt.want) {
+				t.Errorf("truncateToInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultClickRollupInterval = time.Minute
+	defaultClickRollupDelay    = 5 * time.Minute
+	defaultClickRawRetention   = 90 * 24 * time.Hour
+	clickRollupTimeout         = 5 * time.Minute
+	clickPruneBatchSize        = 10000
+)
+
+// RollupClickStats 将已结束且超过汇总滞后时间的整点小时汇总到小时和天汇总表，返回新的汇总进度；
+// 其他实例正在汇总时返回零值
+func (s *ShortLinkService) RollupClickStats(ctx context.Context) (time.Time, error) {
+	ctx, span := startSpan(ctx, "RollupClickStats")
+	defer span.End()
+
+	delay := s.config.Analytics.RollupDelay
+	if delay <= 0 {
+		delay = defaultClickRollupDelay
+	}
+	until := time.Now().Add(-delay).UTC().Truncate(time.Hour)
+
+	for {
+		watermark, err := s.repo.RollupClickStats(ctx, until)
+		if err != nil || watermark.IsZero() || !watermark.Before(until) {
+			return watermark, err
+		}
+	}
+}
+
+// PruneClickEvents 删除超过保留时长的原始点击事件，只删除汇总进度之前的事件，返回删除的条数
+func (s *ShortLinkService) PruneClickEvents(ctx context.Context, watermark time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "PruneClickEvents")
+	defer span.End()
+
+	retention := s.config.Analytics.RawRetention
+	if retention <= 0 {
+		retention = defaultClickRawRetention
+	}
+	before := time.Now().Add(-retention)
+	if watermark.Before(before) {
+		before = watermark
+	}
+
+	var total int64
+	for {
+		deleted, err := s.repo.PruneClickEvents(ctx, before, clickPruneBatchSize)
+		total += deleted
+		if err != nil || deleted < clickPruneBatchSize {
+			return total, err
+		}
+	}
+}
+
+// runClickRollup 周期性汇总点击事件并清理过期的原始事件
+func (s *ShortLinkService) runClickRollup(interval time.Duration) {
+	defer s.workers.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.rollupClickStats()
+		}
+	}
+}
+
+func (s *ShortLinkService) rollupClickStats() {
+	ctx, cancel := context.WithTimeout(context.Background(), clickRollupTimeout)
+	defer cancel()
+
+	watermark, err := s.RollupClickStats(ctx)
+	if err != nil {
+		s.logger.Error("failed to roll up click stats", zap.Error(err))
+		return
+	}
+	if watermark.IsZero() {
+		return
+	}
+
+	pruned, err := s.PruneClickEvents(ctx, watermark)
+	if err != nil {
+		s.logger.Error("failed to prune click events", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		s.logger.Info("pruned click events", zap.Int64("events", pruned), zap.Time("watermark", watermark))
+	}
+}
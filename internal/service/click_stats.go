@@ -0,0 +1,109 @@
+package service
+
+import (
+	"short-url/internal/models"
+	"sort"
+	"time"
+)
+
+// 汇总表的统计维度：total 的 key 为空，referrer 的 key 为来源域名（空表示直接访问），device 的 key 为设备类型
+const (
+	statsDimensionTotal    = "total"
+	statsDimensionReferrer = "referrer"
+	statsDimensionDevice   = "device"
+)
+
+// maxClickRollupSpan 单次汇总推进的最大时间跨度，避免首次汇总或长时间停机后一次事务过大
+const maxClickRollupSpan = 24 * time.Hour
+
+// clickStats 合并汇总表与原始点击事件的统计结果
+type clickStats struct {
+	interval  string
+	series    map[time.Time]int64
+	referrers map[string]int64
+	devices   map[string]int64
+	total     int64
+}
+
+func newClickStats(interval string) *clickStats {
+	return &clickStats{
+		interval:  interval,
+		series:    make(map[time.Time]int64),
+		referrers: make(map[string]int64),
+		devices:   make(map[string]int64),
+	}
+}
+
+// addClicks 累加一组原始点击事件
+func (c *clickStats) addClicks(clickedAt time.Time, referrerDomain, deviceClass string, clicks int64) {
+	c.series[truncateToInterval(clickedAt, c.interval)] += clicks
+	c.referrers[referrerDomain] += clicks
+	c.devices[deviceClass] += clicks
+	c.total += clicks
+}
+
+// addRollup 累加一条汇总记录
+func (c *clickStats) addRollup(bucket time.Time, dimension, key string, clicks int64) {
+	switch dimension {
+	case statsDimensionTotal:
+		c.series[truncateToInterval(bucket, c.interval)] += clicks
+		c.total += clicks
+	case statsDimensionReferrer:
+		c.referrers[key] += clicks
+	case statsDimensionDevice:
+		c.devices[key] += clicks
+	}
+}
+
+// analytics 生成按时间升序的序列和前 TopN 项分布
+func (c *clickStats) analytics(query AnalyticsQuery) *models.LinkAnalytics {
+	analytics := &models.LinkAnalytics{
+		ShortCode:   query.ShortCode,
+		From:        query.From,
+		To:          query.To,
+		Interval:    query.Interval,
+		TotalClicks: c.total,
+	}
+
+	for bucket, clicks := range c.series {
+		analytics.Series = append(analytics.Series, models.AnalyticsPoint{Time: bucket, Clicks: clicks})
+	}
+	sort.Slice(analytics.Series, func(i, j int) bool {
+		return analytics.Series[i].Time.Before(analytics.Series[j].Time)
+	})
+
+	analytics.Referrers = topBreakdown(c.referrers, query.TopN)
+	analytics.Devices = topBreakdown(c.devices, query.TopN)
+
+	return analytics
+}
+
+// topBreakdown 按点击数降序取前 n 项
+func topBreakdown(counts map[string]int64, n int) []models.AnalyticsBreakdown {
+	breakdown := make([]models.AnalyticsBreakdown, 0, len(counts))
+	for key, clicks := range counts {
+		breakdown = append(breakdown, models.AnalyticsBreakdown{Key: key, Clicks: clicks})
+	}
+
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Clicks != breakdown[j].Clicks {
+			return breakdown[i].Clicks > breakdown[j].Clicks
+		}
+		return breakdown[i].Key < breakdown[j].Key
+	})
+	if n > 0 && n < len(breakdown) {
+		breakdown = breakdown[:n]
+	}
+
+	return breakdown
+}
+
+// rollupReadRange 计算查询范围中从汇总表读取的部分 [from, to)：起点按小时向下对齐，终点为汇总进度
+func rollupReadRange(query AnalyticsQuery, watermark time.Time) (time.Time, time.Time) {
+	from := query.From.UTC().Truncate(time.Hour)
+	to := watermark
+	if query.To.Before(to) {
+		to = query.To
+	}
+	return from, to
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"sync/atomic"
+)
+
+// GenerationStats 短码生成的重试计数器
+type GenerationStats struct {
+	// Retries 候选短码已被占用而重新生成的次数
+	Retries atomic.Int64
+	// Failures 重试次数耗尽仍未生成可用短码的次数
+	Failures atomic.Int64
+	// BloomFalsePositives 布隆过滤器判断可能存在、数据库确认不存在的次数
+	BloomFalsePositives atomic.Int64
+}
+
+// GenerationStatsSnapshot 短码生成计数器快照
+type GenerationStatsSnapshot struct {
+	Retries             int64 `json:"retries"`
+	Failures            int64 `json:"failures"`
+	BloomFalsePositives int64 `json:"bloom_false_positives"`
+}
+
+// Snapshot 读取当前计数
+func (g *GenerationStats) Snapshot() GenerationStatsSnapshot {
+	return GenerationStatsSnapshot{
+		Retries:             g.Retries.Load(),
+		Failures:            g.Failures.Load(),
+		BloomFalsePositives: g.BloomFalsePositives.Load(),
+	}
+}
+
+// GenerationStats 获取短码生成的重试计数
+func (s *ShortLinkService) GenerationStats() GenerationStatsSnapshot {
+	return s.generationStats.Snapshot()
+}
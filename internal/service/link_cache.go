@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"short-url/internal/cache"
+	"short-url/internal/models"
+	"time"
+)
+
+const defaultNegativeCacheTTL = 30 * time.Second
+
+// cachedLink 缓存中保存的重定向元数据，Missing 表示短码不存在的负缓存条目
+type cachedLink struct {
+	OriginalURL  string     `json:"url,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	StatusReason string     `json:"status_reason,omitempty"`
+	Missing      bool       `json:"missing,omitempty"`
+}
+
+// IsExpired 检查缓存的链接是否已过期
+func (l *cachedLink) IsExpired() bool {
+	return l.ExpiresAt != nil && time.Now().After(*l.ExpiresAt)
+}
+
+// cacheLink 缓存短链接
+func (s *ShortLinkService) cacheLink(ctx context.Context, shortLink *models.ShortLink) error {
+	return s.setCachedLink(ctx, shortLink.ShortCode, s.newCachedLink(shortLink), s.linkTTL(shortLink))
+}
+
+// cacheLinks 通过一次批量写入缓存多个短链接
+func (s *ShortLinkService) cacheLinks(ctx context.Context, shortLinks []*models.ShortLink) error {
+	entries := make([]cache.Entry, 0, len(shortLinks))
+	for _, shortLink := range shortLinks {
+		entry, err := s.newCacheEntry(shortLink.ShortCode, s.newCachedLink(shortLink), s.linkTTL(shortLink))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	return s.cache.SetMulti(ctx, entries)
+}
+
+// linkTTL 计算短链接的缓存 TTL，取默认 TTL 与距过期时间的较小值；
+// 已过期的链接按负缓存 TTL 短暂缓存，避免重复查询数据库
+func (s *ShortLinkService) linkTTL(shortLink *models.ShortLink) time.Duration {
+	ttl := s.config.Cache.TTL
+	if shortLink.ExpiresAt != nil {
+		untilExpiry := time.Until(*shortLink.ExpiresAt)
+		if untilExpiry <= 0 {
+			untilExpiry = s.negativeTTL()
+		}
+		if ttl <= 0 || untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+
+	return ttl
+}
+
+func (s *ShortLinkService) newCachedLink(shortLink *models.ShortLink) cachedLink {
+	return cachedLink{
+		OriginalURL:  shortLink.OriginalURL,
+		ExpiresAt:    shortLink.ExpiresAt,
+		Status:       shortLink.Status,
+		StatusReason: shortLink.StatusReason,
+	}
+}
+
+// cacheMissing 为不存在的短码写入短 TTL 的负缓存条目
+func (s *ShortLinkService) cacheMissing(ctx context.Context, shortCode string) error {
+	return s.setCachedLink(ctx, shortCode, cachedLink{Missing: true}, s.negativeTTL())
+}
+
+func (s *ShortLinkService) setCachedLink(ctx context.Context, shortCode string, link cachedLink, ttl time.Duration) error {
+	entry, err := s.newCacheEntry(shortCode, link, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Set(ctx, entry.Key, entry.Value, entry.Expiration)
+}
+
+func (s *ShortLinkService) newCacheEntry(shortCode string, link cachedLink, ttl time.Duration) (cache.Entry, error) {
+	value, err := json.Marshal(link)
+	if err != nil {
+		return cache.Entry{}, fmt.Errorf("failed to encode cached link: %w", err)
+	}
+
+	return cache.Entry{Key: s.cacheKey(shortCode), Value: string(value), Expiration: ttl}, nil
+}
+
+func (s *ShortLinkService) negativeTTL() time.Duration {
+	if s.config.Cache.NegativeTTL > 0 {
+		return s.config.Cache.NegativeTTL
+	}
+	return defaultNegativeCacheTTL
+}
+
+// getCachedLink 读取缓存的短链接，无法解析的旧格式值按未命中处理
+func (s *ShortLinkService) getCachedLink(ctx context.Context, shortCode string) (*cachedLink, error) {
+	value, err := s.cache.Get(ctx, s.cacheKey(shortCode))
+	if err != nil {
+		return nil, err
+	}
+
+	var link cachedLink
+	if err := json.Unmarshal([]byte(value), &link); err != nil || (link.OriginalURL == "" && !link.Missing) {
+		return nil, cache.ErrCacheMiss
+	}
+
+	return &link, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"short-url/internal/models"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// listCursor 编码到不透明游标中的内容，Sort 用于拒绝与当前排序不一致的游标
+type listCursor struct {
+	Sort string `json:"s"`
+	ShortLinkCursor
+}
+
+// ListShortLinks 按条件分页列出短链接，使用键集游标而非 OFFSET
+func (s *ShortLinkService) ListShortLinks(ctx context.Context, req *models.ListShortLinksRequest) (*models.ListShortLinksResponse, error) {
+	ctx, span := startSpan(ctx, "ListShortLinks")
+	defer span.End()
+
+	filter := ShortLinkFilter{
+		CreatedAfter:   req.CreatedAfter,
+		CreatedBefore:  req.CreatedBefore,
+		Expiry:         req.Expiry,
+		Status:         req.Status,
+		Domain:         req.Domain,
+		MinAccessCount: req.MinAccessCount,
+		SortBy:         req.Sort,
+		Limit:          req.Limit,
+	}
+
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByAccessCount:
+	default:
+		return nil, fmt.Errorf("%w: sort must be %q or %q", ErrInvalidQuery, SortByCreatedAt, SortByAccessCount)
+	}
+
+	switch filter.Expiry {
+	case "", ExpiryFilterActive, ExpiryFilterExpired, ExpiryFilterPermanent:
+	default:
+		return nil, fmt.Errorf("%w: expiry must be %q, %q or %q", ErrInvalidQuery, ExpiryFilterActive, ExpiryFilterExpired, ExpiryFilterPermanent)
+	}
+
+	// 列表不包含已删除的短链接，只能按启用和停用筛选
+	switch filter.Status {
+	case "", models.LinkStatusActive, models.LinkStatusDisabled:
+	default:
+		return nil, fmt.Errorf("%w: status must be %q or %q", ErrInvalidQuery, models.LinkStatusActive, models.LinkStatusDisabled)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeListCursor(req.Cursor, filter.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	// 多取一条用于判断是否还有下一页
+	pageSize := filter.Limit
+	filter.Limit++
+
+	shortLinks, err := s.repo.ListShortLinks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list short links: %w", err)
+	}
+
+	response := &models.ListShortLinksResponse{Items: shortLinks}
+	if len(shortLinks) > pageSize {
+		response.Items = shortLinks[:pageSize]
+		last := response.Items[pageSize-1]
+		response.NextCursor = encodeListCursor(filter.SortBy, last)
+	}
+	if response.Items == nil {
+		response.Items = []*models.ShortLink{}
+	}
+
+	return response, nil
+}
+
+func encodeListCursor(sortBy string, last *models.ShortLink) string {
+	cursor := listCursor{Sort: sortBy, ShortLinkCursor: ShortLinkCursor{ID: last.ID}}
+	if sortBy == SortByAccessCount {
+		cursor.AccessCount = last.AccessCount
+	} else {
+		cursor.CreatedAt = last.CreatedAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListCursor(value, sortBy string) (*ShortLinkCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if cursor.Sort != sortBy {
+		return nil, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidQuery)
+	}
+
+	return &cursor.ShortLinkCursor, nil
+}
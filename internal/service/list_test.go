@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"short-url/internal/config"
+	"short-url/internal/models"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	last := &models.ShortLink{ID: 42, CreatedAt: createdAt, AccessCount: 7}
+
+	tests := []struct {
+		sortBy string
+		want   ShortLinkCursor
+	}{
+		{sortBy: SortByCreatedAt, want: ShortLinkCursor{ID: 42, CreatedAt: createdAt}},
+		{sortBy: SortByAccessCount, want: ShortLinkCursor{ID: 42, AccessCount: 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			encoded := encodeListCursor(tt.sortBy, last)
+			got, err := decodeListCursor(encoded, tt.sortBy)
+			if err != nil {
+				t.Fatalf("decodeListCursor() error = %v", err)
+			}
+			if got.ID != tt.want.ID || got.AccessCount != tt.want.AccessCount || !got.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Errorf("decodeListCursor() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeListCursorInvalid(t *testing.T) {
+	valid := encodeListCursor(SortByCreatedAt, &models.ShortLink{ID: 1, CreatedAt: time.Now()})
+
+	tests := []struct {
+		name   string
+		value  string
+		sortBy string
+	}{
+		{name: "not base64", value: "not a cursor!", sortBy: SortByCreatedAt},
+		{name: "not json", value: base64.RawURLEncoding.EncodeToString([]byte("{")), sortBy: SortByCreatedAt},
+		{name: "wrong json type", value: base64.RawURLEncoding.EncodeToString([]byte(`{"i":"x"}`)), sortBy: SortByCreatedAt},
+		{name: "padded base64", value: valid + "==", sortBy: SortByCreatedAt},
+		{name: "sort mismatch", value: valid, sortBy: SortByAccessCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeListCursor(tt.value, tt.sortBy); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("decodeListCursor() error = %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
+
+func newListTestService(t *testing.T, accessCounts []int64) *ShortLinkService {
+	t.Helper()
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	deltas := make(map[string]int64)
+	for i, count := range accessCounts {
+		code := fmt.Sprintf("code%02d", i)
+		if err := repo.CreateShortLink(ctx, &models.ShortLink{ShortCode: code, OriginalURL: "https://example.com/" + code}); err != nil {
+			t.Fatalf("CreateShortLink() error = %v", err)
+		}
+		deltas[code] = count
+	}
+	if err := repo.IncrementAccessCounts(ctx, deltas); err != nil {
+		t.Fatalf("IncrementAccessCounts() error = %v", err)
+	}
+
+	return NewShortLinkService(repo, nil, nil, nil, nil, &config.Config{}, zap.NewNop())
+}
+
+func TestListShortLinksPaging(t *testing.T) {
+	// 访问次数有重复，检查游标在并列时按 ID 继续
+	accessCounts := []int64{5, 3, 5, 0, 3, 9, 1}
+
+	tests := []struct {
+		sortBy string
+		limit  int
+	}{
+		{sortBy: SortByCreatedAt, limit: 2},
+		{sortBy: SortByCreatedAt, limit: 7},
+		{sortBy: SortByAccessCount, limit: 2},
+		{sortBy: SortByAccessCount, limit: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.sortBy, tt.limit), func(t *testing.T) {
+			ctx := context.Background()
+			svc := newListTestService(t, accessCounts)
+
+			seen := make(map[string]bool)
+			var previous *models.ShortLink
+			cursor := ""
+			for page := 0; ; page++ {
+				if page > len(accessCounts) {
+					t.Fatal("paging did not terminate")
+				}
+				resp, err := svc.ListShortLinks(ctx, &models.ListShortLinksRequest{Sort: tt.sortBy, Limit: tt.limit, Cursor: cursor})
+				if err != nil {
+					t.Fatalf("ListShortLinks() error = %v", err)
+				}
+				if len(resp.Items) > tt.limit {
+					t.Fatalf("page has %d items, want at most %d", len(resp.Items), tt.limit)
+				}
+
+				for _, item := range resp.Items {
+					if seen[item.ShortCode] {
+						t.Errorf("%s returned twice", item.ShortCode)
+					}
+					seen[item.ShortCode] = true
+					if previous != nil && tt.sortBy == SortByAccessCount && item.AccessCount > previous.AccessCount {
+						t.Errorf("%s (%d) sorted after %s (%d)", item.ShortCode, item.AccessCount, previous.ShortCode, previous.AccessCount)
+					}
+					previous = item
+				}
+
+				if resp.NextCursor == "" {
+					break
+				}
+				cursor = resp.NextCursor
+			}
+
+			if len(seen) != len(accessCounts) {
+				t.Errorf("listed %d links, want %d", len(seen), len(accessCounts))
+			}
+		})
+	}
+}
+
+func TestListShortLinksInvalidQuery(t *testing.T) {
+	cursor := encodeListCursor(SortByCreatedAt, &models.ShortLink{ID: 1, CreatedAt: time.Now()})
+
+	tests := []struct {
+		name string
+		req  models.ListShortLinksRequest
+	}{
+		{name: "unknown sort", req: models.ListShortLinksRequest{Sort: "short_code"}},
+		{name: "unknown expiry", req: models.ListShortLinksRequest{Expiry: "soon"}},
+		{name: "deleted status", req: models.ListShortLinksRequest{Status: models.LinkStatusDeleted}},
+		{name: "malformed cursor", req: models.ListShortLinksRequest{Cursor: "%%%"}},
+		{name: "cursor from other sort", req: models.ListShortLinksRequest{Sort: SortByAccessCount, Cursor: cursor}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newListTestService(t, nil)
+			if _, err := svc.ListShortLinks(context.Background(), &tt.req); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("ListShortLinks() error = %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/models"
+	"short-url/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxLookupResults = 100
+	reuseLookupLimit = 20
+)
+
+// LookupByURL 按原始URL反查短链接，URL 先按创建时的规则标准化
+func (s *ShortLinkService) LookupByURL(ctx context.Context, rawURL string) (*models.LookupShortLinksResponse, error) {
+	ctx, span := startSpan(ctx, "LookupByURL")
+	defer span.End()
+
+	if !utils.IsValidURL(rawURL) {
+		return nil, ErrInvalidURL
+	}
+
+	normalizedURL := utils.NormalizeURL(rawURL)
+	shortLinks, err := s.repo.FindShortLinksByURL(ctx, normalizedURL, maxLookupResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup short links: %w", err)
+	}
+	if shortLinks == nil {
+		shortLinks = []*models.ShortLink{}
+	}
+
+	return &models.LookupShortLinksResponse{URL: normalizedURL, Items: shortLinks}, nil
+}
+
+// findReusableLink 查找相同URL下最新的、未停用且未过期的短链接。
+// 查询失败时只记录日志，调用方继续创建新短链接
+func (s *ShortLinkService) findReusableLink(ctx context.Context, normalizedURL string) *models.ShortLink {
+	shortLinks, err := s.repo.FindShortLinksByURL(ctx, normalizedURL, reuseLookupLimit)
+	if err != nil {
+		s.loggerFor(ctx).Warn("failed to lookup existing short link", zap.Error(err))
+		return nil
+	}
+
+	for _, shortLink := range shortLinks {
+		if shortLink.Status == models.LinkStatusActive && !shortLink.IsExpired() {
+			return shortLink
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"short-url/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCreateShortLinkReuseExisting(t *testing.T) {
+	const url = "https://example.com/page"
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		// existing 为相同URL下已有短链接的状态，为空时没有已有短链接
+		existing  string
+		expiresAt *time.Time
+		req       models.CreateShortLinkRequest
+		wantReuse bool
+	}{
+		{name: "no existing link", req: models.CreateShortLinkRequest{URL: url, ReuseExisting: true}},
+		{name: "active link reused", existing: models.LinkStatusActive, req: models.CreateShortLinkRequest{URL: url, ReuseExisting: true}, wantReuse: true},
+		{
+			name:      "normalized URL matches",
+			existing:  models.LinkStatusActive,
+			req:       models.CreateShortLinkRequest{URL: "https://EXAMPLE.com/page/", ReuseExisting: true},
+			wantReuse: true,
+		},
+		{name: "reuse not requested", existing: models.LinkStatusActive, req: models.CreateShortLinkRequest{URL: url}},
+		{name: "custom code never reuses", existing: models.LinkStatusActive, req: models.CreateShortLinkRequest{URL: url, CustomCode: "mine01", ReuseExisting: true}},
+		{name: "disabled link skipped", existing: models.LinkStatusDisabled, req: models.CreateShortLinkRequest{URL: url, ReuseExisting: true}},
+		{name: "deleted link skipped", existing: models.LinkStatusDeleted, req: models.CreateShortLinkRequest{URL: url, ReuseExisting: true}},
+		{name: "expired link skipped", existing: models.LinkStatusActive, expiresAt: &past, req: models.CreateShortLinkRequest{URL: url, ReuseExisting: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo, _ := newTestService(t)
+			if tt.existing != "" {
+				existing := &models.ShortLink{ShortCode: "old001", OriginalURL: url, ExpiresAt: tt.expiresAt, Status: tt.existing}
+				if err := repo.CreateShortLink(ctx, existing); err != nil {
+					t.Fatalf("CreateShortLink() error = %v", err)
+				}
+			}
+
+			resp, err := svc.CreateShortLink(ctx, &tt.req)
+			if err != nil {
+				t.Fatalf("CreateShortLink() error = %v", err)
+			}
+			if resp.Reused != tt.wantReuse {
+				t.Errorf("Reused = %v, want %v", resp.Reused, tt.wantReuse)
+			}
+			if reusedCode := resp.ShortCode == "old001"; reusedCode != tt.wantReuse {
+				t.Errorf("ShortCode = %q, reused old001 = %v, want %v", resp.ShortCode, reusedCode, tt.wantReuse)
+			}
+			if resp.OriginalURL != url {
+				t.Errorf("OriginalURL = %q, want %q", resp.OriginalURL, url)
+			}
+		})
+	}
+}
+
+func TestLookupByURL(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestService(t)
+	links := []*models.ShortLink{
+		{ShortCode: "page01", OriginalURL: "https://example.com/page"},
+		{ShortCode: "page02", OriginalURL: "https://example.com/page", Status: models.LinkStatusDisabled},
+		{ShortCode: "page03", OriginalURL: "https://example.com/page", Status: models.LinkStatusDeleted},
+		{ShortCode: "other1", OriginalURL: "https://example.com/other"},
+	}
+	for _, shortLink := range links {
+		if err := repo.CreateShortLink(ctx, shortLink); err != nil {
+			t.Fatalf("CreateShortLink() error = %v", err)
+		}
+	}
+
+	resp, err := svc.LookupByURL(ctx, "https://Example.COM/page/")
+	if err != nil {
+		t.Fatalf("LookupByURL() error = %v", err)
+	}
+	if resp.URL != "https://example.com/page" {
+		t.Errorf("URL = %q, want https://example.com/page", resp.URL)
+	}
+
+	// 最新创建的在前，已删除的不返回
+	var codes []string
+	for _, item := range resp.Items {
+		codes = append(codes, item.ShortCode)
+	}
+	if len(codes) != 2 || codes[0] != "page02" || codes[1] != "page01" {
+		t.Errorf("codes = %v, want [page02 page01]", codes)
+	}
+
+	if _, err := svc.LookupByURL(ctx, "not a url"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("LookupByURL(invalid) error = %v, want %v", err, ErrInvalidURL)
+	}
+}
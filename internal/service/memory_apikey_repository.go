@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type memoryAPIKey struct {
+	key     models.APIKey
+	keyHash string
+}
+
+// CreateAPIKey 保存 API key
+func (r *MemoryRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.apiKeys {
+		if stored.keyHash == keyHash {
+			return fmt.Errorf("failed to create API key: duplicate key hash")
+		}
+	}
+
+	key.ID = int64(len(r.apiKeys)) + 1
+	key.CreatedAt = time.Now()
+	r.apiKeys = append(r.apiKeys, &memoryAPIKey{key: *copyAPIKey(key), keyHash: keyHash})
+	return nil
+}
+
+// AuthenticateAPIKey 查找未吊销的 API key 并更新最近使用时间
+func (r *MemoryRepository) AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.apiKeys {
+		if stored.keyHash == keyHash && stored.key.RevokedAt == nil {
+			now := time.Now()
+			stored.key.LastUsedAt = &now
+			return copyAPIKey(&stored.key), nil
+		}
+	}
+	return nil, fmt.Errorf("failed to authenticate API key: %w", pgx.ErrNoRows)
+}
+
+// RevokeAPIKey 吊销 API key
+func (r *MemoryRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id <= 0 || id > int64(len(r.apiKeys)) || r.apiKeys[id-1].key.RevokedAt != nil {
+		return fmt.Errorf("failed to revoke API key: %w", pgx.ErrNoRows)
+	}
+	now := time.Now()
+	r.apiKeys[id-1].key.RevokedAt = &now
+	return nil
+}
+
+// ListAPIKeys 按 ID 升序列出全部 API key，包括已吊销的
+func (r *MemoryRepository) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*models.APIKey, 0, len(r.apiKeys))
+	for _, stored := range r.apiKeys {
+		keys = append(keys, copyAPIKey(&stored.key))
+	}
+	return keys, nil
+}
+
+// copyAPIKey 复制 API key，避免调用方修改内部状态
+func copyAPIKey(key *models.APIKey) *models.APIKey {
+	cp := *key
+	cp.Scopes = append([]string(nil), key.Scopes...)
+	if key.LastUsedAt != nil {
+		lastUsedAt := *key.LastUsedAt
+		cp.LastUsedAt = &lastUsedAt
+	}
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		cp.RevokedAt = &revokedAt
+	}
+	return &cp
+}
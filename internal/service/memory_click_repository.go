@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"time"
+)
+
+// InsertClickEvents 追加点击事件
+func (r *MemoryRepository) InsertClickEvents(ctx context.Context, events []*models.ClickEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		cp := *event
+		r.clicks = append(r.clicks, &cp)
+	}
+	return nil
+}
+
+// clickStatKey 内存小时汇总表的主键
+type clickStatKey struct {
+	ShortCode string
+	Bucket    time.Time
+	Dimension string
+	Key       string
+}
+
+// GetClickAnalytics 统计时间范围内的点击序列和来源、设备分布，汇总进度之前读取小时汇总，之后读取原始点击事件
+func (r *MemoryRepository) GetClickAnalytics(ctx context.Context, query AnalyticsQuery) (*models.LinkAnalytics, error) {
+	stats := newClickStats(query.Interval)
+
+	r.mu.RLock()
+	rollupFrom, rollupTo := rollupReadRange(query, r.clickWatermark)
+	for key, clicks := range r.hourlyStats {
+		if key.ShortCode != query.ShortCode || key.Bucket.Before(rollupFrom) || !key.Bucket.Before(rollupTo) {
+			continue
+		}
+		stats.addRollup(key.Bucket, key.Dimension, key.Key, clicks)
+	}
+
+	rawFrom := query.From
+	if rawFrom.Before(r.clickWatermark) {
+		rawFrom = r.clickWatermark
+	}
+	for _, event := range r.clicks {
+		if event.ShortCode != query.ShortCode || event.ClickedAt.Before(rawFrom) || !event.ClickedAt.Before(query.To) {
+			continue
+		}
+		stats.addClicks(event.ClickedAt, event.ReferrerDomain, event.DeviceClass, 1)
+	}
+	r.mu.RUnlock()
+
+	return stats.analytics(query), nil
+}
+
+// RollupClickStats 将汇总进度到 until 之间的点击事件累加到小时汇总
+func (r *MemoryRepository) RollupClickStats(ctx context.Context, until time.Time) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := r.clickWatermark
+	if start.IsZero() {
+		start = until
+		for _, event := range r.clicks {
+			if event.ClickedAt.Before(start) {
+				start = event.ClickedAt.UTC().Truncate(time.Hour)
+			}
+		}
+	}
+
+	end := until
+	if end.Sub(start) > maxClickRollupSpan {
+		end = start.Add(maxClickRollupSpan)
+	}
+	if !start.Before(end) {
+		r.clickWatermark = start
+		return start, nil
+	}
+
+	if r.hourlyStats == nil {
+		r.hourlyStats = make(map[clickStatKey]int64)
+	}
+	for _, event := range r.clicks {
+		if event.ClickedAt.Before(start) || !event.ClickedAt.Before(end) {
+			continue
+		}
+		bucket := event.ClickedAt.UTC().Truncate(time.Hour)
+		r.hourlyStats[clickStatKey{ShortCode: event.ShortCode, Bucket: bucket, Dimension: statsDimensionTotal}]++
+		r.hourlyStats[clickStatKey{ShortCode: event.ShortCode, Bucket: bucket, Dimension: statsDimensionReferrer, Key: event.ReferrerDomain}]++
+		r.hourlyStats[clickStatKey{ShortCode: event.ShortCode, Bucket: bucket, Dimension: statsDimensionDevice, Key: event.DeviceClass}]++
+	}
+
+	r.clickWatermark = end
+	return end, nil
+}
+
+// PruneClickEvents 删除 before 之前的原始点击事件
+func (r *MemoryRepository) PruneClickEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	kept := r.clicks[:0]
+	for _, event := range r.clicks {
+		if event.ClickedAt.Before(before) && (limit < 0 || deleted < int64(limit)) {
+			deleted++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	for i := len(kept); i < len(r.clicks); i++ {
+		r.clicks[i] = nil
+	}
+	r.clicks = kept
+
+	return deleted, nil
+}
+
+// SaveUniqueVisitors 写入按天的独立访客数，并更新短链接全部时间的独立访客数
+func (r *MemoryRepository) SaveUniqueVisitors(ctx context.Context, daily []DailyVisitors, totals map[string]int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dailyVisitors == nil {
+		r.dailyVisitors = make(map[VisitorDay]int64)
+	}
+	for _, d := range daily {
+		r.dailyVisitors[VisitorDay{ShortCode: d.ShortCode, Day: d.Day}] = d.Visitors
+	}
+	for shortCode, total := range totals {
+		if shortLink, ok := r.links[shortCode]; ok {
+			shortLink.UniqueVisitors = total
+		}
+	}
+
+	return nil
+}
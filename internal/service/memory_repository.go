@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"short-url/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryRepository 基于内存的短链接存储，适用于测试和单机演示
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	links  map[string]*models.ShortLink
+	clicks []*models.ClickEvent
+
+	// clickWatermark 之前的点击事件已累加到 hourlyStats
+	clickWatermark time.Time
+	hourlyStats    map[clickStatKey]int64
+
+	dailyVisitors map[VisitorDay]int64
+
+	// apiKeys 按 ID 升序保存
+	apiKeys []*memoryAPIKey
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		links: make(map[string]*models.ShortLink),
+	}
+}
+
+// CreateShortLink 创建短链接
+func (r *MemoryRepository) CreateShortLink(ctx context.Context, shortLink *models.ShortLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.links[shortLink.ShortCode]; exists {
+		return fmt.Errorf("failed to create short link: %w", ErrShortCodeExists)
+	}
+
+	now := time.Now()
+	r.nextID++
+	shortLink.ID = r.nextID
+	shortLink.CreatedAt = now
+	shortLink.UpdatedAt = now
+	if shortLink.Status == "" {
+		shortLink.Status = models.LinkStatusActive
+	}
+
+	r.links[shortLink.ShortCode] = copyShortLink(shortLink)
+	return nil
+}
+
+// CreateShortLinks 批量创建短链接，短码冲突的条目被跳过
+func (r *MemoryRepository) CreateShortLinks(ctx context.Context, shortLinks []*models.ShortLink) ([]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	created := make([]bool, len(shortLinks))
+	for i, shortLink := range shortLinks {
+		if _, exists := r.links[shortLink.ShortCode]; exists {
+			continue
+		}
+
+		r.nextID++
+		shortLink.ID = r.nextID
+		shortLink.CreatedAt = now
+		shortLink.UpdatedAt = now
+		if shortLink.Status == "" {
+			shortLink.Status = models.LinkStatusActive
+		}
+
+		r.links[shortLink.ShortCode] = copyShortLink(shortLink)
+		created[i] = true
+	}
+
+	return created, nil
+}
+
+// GetShortLinkByCode 根据短码获取短链接
+func (r *MemoryRepository) GetShortLinkByCode(ctx context.Context, shortCode string) (*models.ShortLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shortLink, ok := r.links[shortCode]
+	if !ok {
+		return nil, fmt.Errorf("short link not found: %w", pgx.ErrNoRows)
+	}
+
+	return copyShortLink(shortLink), nil
+}
+
+// UpdateShortLink 更新短链接的原始URL和过期时间
+func (r *MemoryRepository) UpdateShortLink(ctx context.Context, shortLink *models.ShortLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.links[shortLink.ShortCode]
+	if !ok || stored.Status == models.LinkStatusDeleted {
+		return fmt.Errorf("short link not found or deleted: %w", pgx.ErrNoRows)
+	}
+
+	updated := copyShortLink(shortLink)
+	stored.OriginalURL = updated.OriginalURL
+	stored.ExpiresAt = updated.ExpiresAt
+	stored.UpdatedAt = time.Now()
+
+	shortLink.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// SetShortLinkStatus 设置短链接状态
+func (r *MemoryRepository) SetShortLinkStatus(ctx context.Context, shortCode, status, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	shortLink, ok := r.links[shortCode]
+	if !ok || shortLink.Status == models.LinkStatusDeleted {
+		return fmt.Errorf("short link not found or deleted: %w", pgx.ErrNoRows)
+	}
+
+	shortLink.Status = status
+	shortLink.StatusReason = reason
+	shortLink.UpdatedAt = time.Now()
+	return nil
+}
+
+// ShortCodeExists 检查短码是否存在
+func (r *MemoryRepository) ShortCodeExists(ctx context.Context, shortCode string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.links[shortCode]
+	return ok, nil
+}
+
+// IncrementAccessCount 增加访问次数
+func (r *MemoryRepository) IncrementAccessCount(ctx context.Context, shortCode string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	shortLink, ok := r.links[shortCode]
+	if !ok {
+		return fmt.Errorf("short link not found")
+	}
+
+	shortLink.AccessCount++
+	shortLink.UpdatedAt = time.Now()
+	return nil
+}
+
+// IncrementAccessCounts 批量累加访问次数
+func (r *MemoryRepository) IncrementAccessCounts(ctx context.Context, deltas map[string]int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for shortCode, delta := range deltas {
+		if shortLink, ok := r.links[shortCode]; ok {
+			shortLink.AccessCount += delta
+			shortLink.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+// GetShortLinksByTimeRange 根据时间范围获取短链接列表
+func (r *MemoryRepository) GetShortLinksByTimeRange(ctx context.Context, start, end time.Time, limit, offset int) ([]*models.ShortLink, error) {
+	r.mu.RLock()
+	var matched []*models.ShortLink
+	for _, shortLink := range r.links {
+		if shortLink.CreatedAt.Before(start) || shortLink.CreatedAt.After(end) {
+			continue
+		}
+		matched = append(matched, copyShortLink(shortLink))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	return matched, nil
+}
+
+// FindShortLinksByURL 按标准化后的原始URL反查短链接，不包含已删除的短链接，按创建时间倒序
+func (r *MemoryRepository) FindShortLinksByURL(ctx context.Context, originalURL string, limit int) ([]*models.ShortLink, error) {
+	r.mu.RLock()
+	var matched []*models.ShortLink
+	for _, shortLink := range r.links {
+		if shortLink.OriginalURL == originalURL && shortLink.Status != models.LinkStatusDeleted {
+			matched = append(matched, copyShortLink(shortLink))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	return matched, nil
+}
+
+// ListShortLinks 按条件和键集分页查询短链接
+func (r *MemoryRepository) ListShortLinks(ctx context.Context, filter ShortLinkFilter) ([]*models.ShortLink, error) {
+	domain := strings.ToLower(filter.Domain)
+	byAccessCount := filter.SortBy == SortByAccessCount
+
+	// less 判断 a 是否排在 b 之前（降序）
+	less := func(a, b *models.ShortLink) bool {
+		if byAccessCount {
+			if a.AccessCount != b.AccessCount {
+				return a.AccessCount > b.AccessCount
+			}
+		} else if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	}
+
+	var after *models.ShortLink
+	if filter.After != nil {
+		after = &models.ShortLink{ID: filter.After.ID, CreatedAt: filter.After.CreatedAt, AccessCount: filter.After.AccessCount}
+	}
+
+	r.mu.RLock()
+	var matched []*models.ShortLink
+	for _, shortLink := range r.links {
+		if shortLink.Status == models.LinkStatusDeleted {
+			continue
+		}
+		if filter.CreatedAfter != nil && shortLink.CreatedAt.Before(*filter.CreatedAfter) {
+			continue
+		}
+		if filter.CreatedBefore != nil && !shortLink.CreatedAt.Before(*filter.CreatedBefore) {
+			continue
+		}
+		switch filter.Expiry {
+		case ExpiryFilterActive:
+			if shortLink.ExpiresAt == nil || shortLink.IsExpired() {
+				continue
+			}
+		case ExpiryFilterExpired:
+			if !shortLink.IsExpired() {
+				continue
+			}
+		case ExpiryFilterPermanent:
+			if shortLink.ExpiresAt != nil {
+				continue
+			}
+		}
+		if filter.Status != "" && shortLink.Status != filter.Status {
+			continue
+		}
+		if domain != "" && destinationHost(shortLink.OriginalURL) != domain {
+			continue
+		}
+		if shortLink.AccessCount < filter.MinAccessCount {
+			continue
+		}
+		if after != nil && !less(after, shortLink) {
+			continue
+		}
+		matched = append(matched, copyShortLink(shortLink))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+	if filter.Limit >= 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+
+	return matched, nil
+}
+
+// destinationHost 提取目标地址的小写主机名，与数据库中的 destination_host 列一致
+func destinationHost(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
+
+// DeleteExpiredLinks 将过期的短链接标记为已删除
+func (r *MemoryRepository) DeleteExpiredLinks(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted []string
+	for _, shortLink := range r.links {
+		if shortLink.IsExpired() && shortLink.Status != models.LinkStatusDeleted {
+			shortLink.Status = models.LinkStatusDeleted
+			shortLink.StatusReason = "expired"
+			shortLink.UpdatedAt = time.Now()
+			deleted = append(deleted, shortLink.ShortCode)
+		}
+	}
+
+	return deleted, nil
+}
+
+// GetStats 获取统计信息
+func (r *MemoryRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	var totalLinks, totalAccesses, activeLinks, expiredLinks, disabledLinks, deletedLinks int64
+	var expiringDay, expiringWeek int64
+	for _, shortLink := range r.links {
+		totalLinks++
+		totalAccesses += shortLink.AccessCount
+		switch shortLink.Status {
+		case models.LinkStatusDisabled:
+			disabledLinks++
+		case models.LinkStatusDeleted:
+			deletedLinks++
+		}
+		if shortLink.ExpiresAt == nil {
+			continue
+		}
+		if shortLink.Status == models.LinkStatusActive && shortLink.ExpiresAt.After(now) {
+			if !shortLink.ExpiresAt.After(now.Add(expiringSoonDay)) {
+				expiringDay++
+			}
+			if !shortLink.ExpiresAt.After(now.Add(expiringSoonWeek)) {
+				expiringWeek++
+			}
+		}
+		if shortLink.IsExpired() {
+			expiredLinks++
+		} else {
+			activeLinks++
+		}
+	}
+
+	stats := map[string]interface{}{
+		"total_links":           totalLinks,
+		"total_accesses":        totalAccesses,
+		"active_links":          activeLinks,
+		"expired_links":         expiredLinks,
+		"permanent_links":       totalLinks - activeLinks - expiredLinks,
+		"disabled_links":        disabledLinks,
+		"deleted_links":         deletedLinks,
+		"counters_refreshed_at": now.UTC(),
+		"expiring_within_24h":   expiringDay,
+		"expiring_within_7d":    expiringWeek,
+	}
+
+	return stats, nil
+}
+
+// ScanShortCodes 按 ID 升序分批读取短码
+func (r *MemoryRepository) ScanShortCodes(ctx context.Context, since time.Time, afterID int64, limit int) ([]string, int64, error) {
+	r.mu.RLock()
+	var matched []*models.ShortLink
+	for _, shortLink := range r.links {
+		if shortLink.ID > afterID && !shortLink.CreatedAt.Before(since) {
+			matched = append(matched, shortLink)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	lastID := afterID
+	codes := make([]string, len(matched))
+	for i, shortLink := range matched {
+		codes[i] = shortLink.ShortCode
+		lastID = shortLink.ID
+	}
+
+	return codes, lastID, nil
+}
+
+// copyShortLink 复制短链接，避免调用方修改内部状态
+func copyShortLink(shortLink *models.ShortLink) *models.ShortLink {
+	cp := *shortLink
+	if shortLink.ExpiresAt != nil {
+		expiresAt := *shortLink.ExpiresAt
+		cp.ExpiresAt = &expiresAt
+	}
+	return &cp
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"sort"
+	"time"
+)
+
+// RefreshStatsSummary 内存存储的全局计数实时统计，无需刷新
+func (r *MemoryRepository) RefreshStatsSummary(ctx context.Context) error {
+	return nil
+}
+
+// RollupLinkCounts 内存存储的每日新建数实时统计，直接返回 until
+func (r *MemoryRepository) RollupLinkCounts(ctx context.Context, until time.Time) (time.Time, error) {
+	return until, nil
+}
+
+// GetWindowStats 统计 from 之后每天的新建数、点击数和点击最多的 topN 个短链接
+func (r *MemoryRepository) GetWindowStats(ctx context.Context, from time.Time, topN int) (*models.StatsWindow, error) {
+	created := make(map[time.Time]int64)
+	clicks := make(map[time.Time]int64)
+	linkClicks := make(map[string]int64)
+
+	r.mu.RLock()
+	for _, shortLink := range r.links {
+		if !shortLink.CreatedAt.Before(from) {
+			created[truncateToInterval(shortLink.CreatedAt, AnalyticsIntervalDay)]++
+		}
+	}
+
+	for key, count := range r.hourlyStats {
+		if key.Dimension != statsDimensionTotal || key.Bucket.Before(from) {
+			continue
+		}
+		clicks[truncateToInterval(key.Bucket, AnalyticsIntervalDay)] += count
+		linkClicks[key.ShortCode] += count
+	}
+
+	rawFrom := from
+	if rawFrom.Before(r.clickWatermark) {
+		rawFrom = r.clickWatermark
+	}
+	for _, event := range r.clicks {
+		if event.ClickedAt.Before(rawFrom) {
+			continue
+		}
+		clicks[truncateToInterval(event.ClickedAt, AnalyticsIntervalDay)]++
+		linkClicks[event.ShortCode]++
+	}
+	r.mu.RUnlock()
+
+	window := &models.StatsWindow{
+		LinksCreated: sortedDailyCounts(created),
+		Clicks:       sortedDailyCounts(clicks),
+	}
+	for _, item := range topBreakdown(linkClicks, topN) {
+		window.TopLinks = append(window.TopLinks, models.TopLink{ShortCode: item.Key, Clicks: item.Clicks})
+	}
+
+	return window, nil
+}
+
+// sortedDailyCounts 将按天的计数转换为按日期升序的序列
+func sortedDailyCounts(counts map[time.Time]int64) []models.DailyCount {
+	series := make([]models.DailyCount, 0, len(counts))
+	for day, count := range counts {
+		series = append(series, models.DailyCount{Day: day, Count: count})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Day.Before(series[j].Day) })
+	return series
+}
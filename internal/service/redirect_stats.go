@@ -0,0 +1,69 @@
+package service
+
+import (
+	"sync/atomic"
+)
+
+// RedirectStats 重定向路径的缓存与数据库访问计数器
+type RedirectStats struct {
+	CacheHits    atomic.Int64
+	CacheMisses  atomic.Int64
+	NegativeHits atomic.Int64
+	BloomRejects atomic.Int64
+	DBLookups    atomic.Int64
+	Collapsed    atomic.Int64
+
+	// BloomPasses 布隆过滤器判断可能存在的次数，BloomFalsePositives 为其中数据库确认不存在的次数
+	BloomPasses         atomic.Int64
+	BloomFalsePositives atomic.Int64
+
+	ClicksRecorded atomic.Int64
+	ClicksDropped  atomic.Int64
+}
+
+// RedirectStatsSnapshot 重定向计数器快照
+type RedirectStatsSnapshot struct {
+	CacheHits    int64   `json:"cache_hits"`
+	CacheMisses  int64   `json:"cache_misses"`
+	NegativeHits int64   `json:"negative_hits"`
+	BloomRejects int64   `json:"bloom_rejects"`
+	DBLookups    int64   `json:"db_lookups"`
+	Collapsed    int64   `json:"collapsed"`
+	HitRatio     float64 `json:"hit_ratio"`
+
+	BloomPasses         int64 `json:"bloom_passes"`
+	BloomFalsePositives int64 `json:"bloom_false_positives"`
+
+	ClicksRecorded int64 `json:"clicks_recorded"`
+	ClicksDropped  int64 `json:"clicks_dropped"`
+}
+
+// Snapshot 读取当前计数
+func (r *RedirectStats) Snapshot() RedirectStatsSnapshot {
+	snapshot := RedirectStatsSnapshot{
+		CacheHits:    r.CacheHits.Load(),
+		CacheMisses:  r.CacheMisses.Load(),
+		NegativeHits: r.NegativeHits.Load(),
+		BloomRejects: r.BloomRejects.Load(),
+		DBLookups:    r.DBLookups.Load(),
+		Collapsed:    r.Collapsed.Load(),
+
+		BloomPasses:         r.BloomPasses.Load(),
+		BloomFalsePositives: r.BloomFalsePositives.Load(),
+
+		ClicksRecorded: r.ClicksRecorded.Load(),
+		ClicksDropped:  r.ClicksDropped.Load(),
+	}
+
+	lookups := snapshot.CacheHits + snapshot.NegativeHits + snapshot.CacheMisses
+	if lookups > 0 {
+		snapshot.HitRatio = float64(snapshot.CacheHits+snapshot.NegativeHits) / float64(lookups)
+	}
+
+	return snapshot
+}
+
+// RedirectStats 获取重定向路径的缓存命中、未命中和合并计数
+func (s *ShortLinkService) RedirectStats() RedirectStatsSnapshot {
+	return s.redirectStats.Snapshot()
+}
@@ -2,15 +2,25 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"short-url/internal/database"
 	"short-url/internal/models"
+	"short-url/internal/utils"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// pgUniqueViolation 唯一约束冲突的 SQLSTATE
+const pgUniqueViolation = "23505"
+
+// shortLinkColumns 查询短链接时的列顺序，与 scanShortLink 对应
+const shortLinkColumns = `id, short_code, original_url, access_count, unique_visitors, created_at, updated_at, expires_at,
+		status, COALESCE(status_reason, '')`
+
 type Repository struct {
 	db *database.DB
 }
@@ -22,40 +32,94 @@ func NewRepository(db *database.DB) *Repository {
 // CreateShortLink 创建短链接
 func (r *Repository) CreateShortLink(ctx context.Context, shortLink *models.ShortLink) error {
 	query := `
-		INSERT INTO short_links (short_code, original_url, expires_at)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at
+		INSERT INTO short_links (short_code, original_url, url_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at, status
 	`
 
-	err := r.db.Pool.QueryRow(ctx, query, shortLink.ShortCode, shortLink.OriginalURL, shortLink.ExpiresAt).
-		Scan(&shortLink.ID, &shortLink.CreatedAt, &shortLink.UpdatedAt)
+	err := r.db.Pool.QueryRow(ctx, query, shortLink.ShortCode, shortLink.OriginalURL, utils.HashURL(shortLink.OriginalURL), shortLink.ExpiresAt).
+		Scan(&shortLink.ID, &shortLink.CreatedAt, &shortLink.UpdatedAt, &shortLink.Status)
 
 	if err != nil {
+		// short_links 上只有 short_code 带唯一约束
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return fmt.Errorf("failed to create short link: %w", ErrShortCodeExists)
+		}
 		return fmt.Errorf("failed to create short link: %w", err)
 	}
 
 	return nil
 }
 
+// CreateShortLinks 用一条多行 INSERT 批量创建短链接，短码冲突的行被跳过。
+// 返回每条是否插入成功，成功的短链接回填 ID 与时间戳。
+func (r *Repository) CreateShortLinks(ctx context.Context, shortLinks []*models.ShortLink) ([]bool, error) {
+	created := make([]bool, len(shortLinks))
+	if len(shortLinks) == 0 {
+		return created, nil
+	}
+
+	codes := make([]string, len(shortLinks))
+	urls := make([]string, len(shortLinks))
+	hashes := make([][]byte, len(shortLinks))
+	expiresAt := make([]*time.Time, len(shortLinks))
+	byCode := make(map[string]int, len(shortLinks))
+	for i, shortLink := range shortLinks {
+		codes[i] = shortLink.ShortCode
+		urls[i] = shortLink.OriginalURL
+		hashes[i] = utils.HashURL(shortLink.OriginalURL)
+		expiresAt[i] = shortLink.ExpiresAt
+		byCode[shortLink.ShortCode] = i
+	}
+
+	query := `
+		INSERT INTO short_links (short_code, original_url, url_hash, expires_at)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::bytea[], $4::timestamptz[])
+		ON CONFLICT (short_code) DO NOTHING
+		RETURNING short_code, id, created_at, updated_at, status
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, codes, urls, hashes, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create short links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortCode string
+		var inserted models.ShortLink
+		if err := rows.Scan(&shortCode, &inserted.ID, &inserted.CreatedAt, &inserted.UpdatedAt, &inserted.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan created short link: %w", err)
+		}
+
+		i, ok := byCode[shortCode]
+		if !ok {
+			continue
+		}
+		shortLinks[i].ID = inserted.ID
+		shortLinks[i].CreatedAt = inserted.CreatedAt
+		shortLinks[i].UpdatedAt = inserted.UpdatedAt
+		shortLinks[i].Status = inserted.Status
+		created[i] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to create short links: %w", err)
+	}
+
+	return created, nil
+}
+
 // GetShortLinkByCode 根据短码获取短链接
 func (r *Repository) GetShortLinkByCode(ctx context.Context, shortCode string) (*models.ShortLink, error) {
 	query := `
-		SELECT id, short_code, original_url, access_count, created_at, updated_at, expires_at
+		SELECT ` + shortLinkColumns + `
 		FROM short_links
 		WHERE short_code = $1
 	`
 
-	shortLink := &models.ShortLink{}
-	err := r.db.Pool.QueryRow(ctx, query, shortCode).Scan(
-		&shortLink.ID,
-		&shortLink.ShortCode,
-		&shortLink.OriginalURL,
-		&shortLink.AccessCount,
-		&shortLink.CreatedAt,
-		&shortLink.UpdatedAt,
-		&shortLink.ExpiresAt,
-	)
-
+	shortLink, err := scanShortLink(r.db.Pool.QueryRow(ctx, query, shortCode))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("short link not found: %w", err)
@@ -66,6 +130,48 @@ func (r *Repository) GetShortLinkByCode(ctx context.Context, shortCode string) (
 	return shortLink, nil
 }
 
+// UpdateShortLink 更新短链接的原始URL和过期时间
+func (r *Repository) UpdateShortLink(ctx context.Context, shortLink *models.ShortLink) error {
+	query := `
+		UPDATE short_links
+		SET original_url = $2, url_hash = $3, expires_at = $4
+		WHERE short_code = $1 AND status <> 'deleted'
+		RETURNING updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query, shortLink.ShortCode, shortLink.OriginalURL, utils.HashURL(shortLink.OriginalURL), shortLink.ExpiresAt).
+		Scan(&shortLink.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("short link not found or deleted: %w", err)
+		}
+		return fmt.Errorf("failed to update short link: %w", err)
+	}
+
+	return nil
+}
+
+// SetShortLinkStatus 设置短链接状态，已删除的短链接不可再变更
+func (r *Repository) SetShortLinkStatus(ctx context.Context, shortCode, status, reason string) error {
+	query := `
+		UPDATE short_links
+		SET status = $2, status_reason = NULLIF($3, '')
+		WHERE short_code = $1 AND status <> 'deleted'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, shortCode, status, reason)
+	if err != nil {
+		return fmt.Errorf("failed to set short link status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("short link not found or deleted: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
 // ShortCodeExists 检查短码是否存在
 func (r *Repository) ShortCodeExists(ctx context.Context, shortCode string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM short_links WHERE short_code = $1)`
@@ -99,17 +205,115 @@ func (r *Repository) IncrementAccessCount(ctx context.Context, shortCode string)
 	return nil
 }
 
+// IncrementAccessCounts 批量累加访问次数
+func (r *Repository) IncrementAccessCounts(ctx context.Context, deltas map[string]int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	codes := make([]string, 0, len(deltas))
+	counts := make([]int64, 0, len(deltas))
+	for shortCode, delta := range deltas {
+		codes = append(codes, shortCode)
+		counts = append(counts, delta)
+	}
+
+	query := `
+		UPDATE short_links AS s
+		SET access_count = s.access_count + d.delta, updated_at = CURRENT_TIMESTAMP
+		FROM unnest($1::text[], $2::bigint[]) AS d(short_code, delta)
+		WHERE s.short_code = d.short_code
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, codes, counts); err != nil {
+		return fmt.Errorf("failed to increment access counts: %w", err)
+	}
+
+	return nil
+}
+
 // GetShortLinksByTimeRange 根据时间范围获取短链接列表
 func (r *Repository) GetShortLinksByTimeRange(ctx context.Context, start, end time.Time, limit, offset int) ([]*models.ShortLink, error) {
 	query := `
-		SELECT id, short_code, original_url, access_count, created_at, updated_at, expires_at
+		SELECT ` + shortLinkColumns + `
 		FROM short_links
 		WHERE created_at BETWEEN $1 AND $2
 		ORDER BY created_at DESC
 		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, start, end, limit, offset)
+	return r.queryShortLinks(ctx, query, start, end, limit, offset)
+}
+
+// FindShortLinksByURL 按标准化后的原始URL反查短链接，不包含已删除的短链接，按创建时间倒序
+func (r *Repository) FindShortLinksByURL(ctx context.Context, originalURL string, limit int) ([]*models.ShortLink, error) {
+	query := `
+		SELECT ` + shortLinkColumns + `
+		FROM short_links
+		WHERE url_hash = $1 AND original_url = $2 AND status <> 'deleted'
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	return r.queryShortLinks(ctx, query, utils.HashURL(originalURL), originalURL, limit)
+}
+
+// ListShortLinks 按条件和键集分页查询短链接
+func (r *Repository) ListShortLinks(ctx context.Context, filter ShortLinkFilter) ([]*models.ShortLink, error) {
+	conditions := []string{"status <> 'deleted'"}
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedBefore))
+	}
+	switch filter.Expiry {
+	case ExpiryFilterActive:
+		conditions = append(conditions, "expires_at > CURRENT_TIMESTAMP")
+	case ExpiryFilterExpired:
+		conditions = append(conditions, "expires_at <= CURRENT_TIMESTAMP")
+	case ExpiryFilterPermanent:
+		conditions = append(conditions, "expires_at IS NULL")
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+arg(filter.Status))
+	}
+	if filter.Domain != "" {
+		conditions = append(conditions, "destination_host = "+arg(strings.ToLower(filter.Domain)))
+	}
+	if filter.MinAccessCount > 0 {
+		conditions = append(conditions, "access_count >= "+arg(filter.MinAccessCount))
+	}
+
+	orderBy := "created_at DESC, id DESC"
+	if filter.SortBy == SortByAccessCount {
+		orderBy = "access_count DESC, id DESC"
+		if filter.After != nil {
+			conditions = append(conditions, fmt.Sprintf("(access_count, id) < (%s, %s)", arg(filter.After.AccessCount), arg(filter.After.ID)))
+		}
+	} else if filter.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(filter.After.CreatedAt), arg(filter.After.ID)))
+	}
+
+	query := `
+		SELECT ` + shortLinkColumns + `
+		FROM short_links
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + arg(filter.Limit)
+
+	return r.queryShortLinks(ctx, query, args...)
+}
+
+// queryShortLinks 执行查询并扫描多行短链接
+func (r *Repository) queryShortLinks(ctx context.Context, query string, args ...interface{}) ([]*models.ShortLink, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get short links: %w", err)
 	}
@@ -117,16 +321,7 @@ func (r *Repository) GetShortLinksByTimeRange(ctx context.Context, start, end ti
 
 	var shortLinks []*models.ShortLink
 	for rows.Next() {
-		shortLink := &models.ShortLink{}
-		err := rows.Scan(
-			&shortLink.ID,
-			&shortLink.ShortCode,
-			&shortLink.OriginalURL,
-			&shortLink.AccessCount,
-			&shortLink.CreatedAt,
-			&shortLink.UpdatedAt,
-			&shortLink.ExpiresAt,
-		)
+		shortLink, err := scanShortLink(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan short link: %w", err)
 		}
@@ -140,47 +335,140 @@ func (r *Repository) GetShortLinksByTimeRange(ctx context.Context, start, end ti
 	return shortLinks, nil
 }
 
-// DeleteExpiredLinks 删除过期的短链接
-func (r *Repository) DeleteExpiredLinks(ctx context.Context) (int64, error) {
+// DeleteExpiredLinks 将过期的短链接标记为已删除。
+// 保留行作为墓碑，短码不会被再次分配
+func (r *Repository) DeleteExpiredLinks(ctx context.Context) ([]string, error) {
 	query := `
-		DELETE FROM short_links
-		WHERE expires_at IS NOT NULL AND expires_at < CURRENT_TIMESTAMP
+		UPDATE short_links
+		SET status = 'deleted', status_reason = 'expired'
+		WHERE expires_at IS NOT NULL AND expires_at < CURRENT_TIMESTAMP AND status <> 'deleted'
+		RETURNING short_code
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query)
+	rows, err := r.db.Pool.Query(ctx, query)
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete expired links: %w", err)
+		return nil, fmt.Errorf("failed to delete expired links: %w", err)
+	}
+	defer rows.Close()
+
+	var shortCodes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan short code: %w", err)
+		}
+		shortCodes = append(shortCodes, code)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete expired links: %w", err)
 	}
 
-	return result.RowsAffected(), nil
+	return shortCodes, nil
 }
 
-// GetStats 获取统计信息
+// GetStats 从物化视图读取全局计数（截至 counters_refreshed_at），即将过期的短链接数通过 expires_at 索引实时统计
 func (r *Repository) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	query := `
-		SELECT 
-			COUNT(*) as total_links,
-			SUM(access_count) as total_accesses,
-			COUNT(*) FILTER (WHERE expires_at IS NOT NULL AND expires_at > CURRENT_TIMESTAMP) as active_links,
-			COUNT(*) FILTER (WHERE expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP) as expired_links
-		FROM short_links
+		SELECT total_links, total_accesses, active_links, expired_links, disabled_links, deleted_links, refreshed_at
+		FROM short_link_summary
 	`
 
-	var totalLinks, totalAccesses int64
-	var activeLinks, expiredLinks sql.NullInt64
+	var totalLinks, totalAccesses, activeLinks, expiredLinks, disabledLinks, deletedLinks int64
+	var refreshedAt time.Time
 
-	err := r.db.Pool.QueryRow(ctx, query).Scan(&totalLinks, &totalAccesses, &activeLinks, &expiredLinks)
+	err := r.db.Pool.QueryRow(ctx, query).Scan(&totalLinks, &totalAccesses, &activeLinks, &expiredLinks,
+		&disabledLinks, &deletedLinks, &refreshedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
 
+	expiringQuery := `
+		SELECT
+			COUNT(*) FILTER (WHERE expires_at <= CURRENT_TIMESTAMP + $1::interval),
+			COUNT(*)
+		FROM short_links
+		WHERE status = 'active' AND expires_at > CURRENT_TIMESTAMP AND expires_at <= CURRENT_TIMESTAMP + $2::interval
+	`
+
+	var expiringDay, expiringWeek int64
+	if err := r.db.Pool.QueryRow(ctx, expiringQuery, expiringSoonDay, expiringSoonWeek).Scan(&expiringDay, &expiringWeek); err != nil {
+		return nil, fmt.Errorf("failed to count expiring links: %w", err)
+	}
+
 	stats := map[string]interface{}{
-		"total_links":     totalLinks,
-		"total_accesses":  totalAccesses,
-		"active_links":    activeLinks.Int64,
-		"expired_links":   expiredLinks.Int64,
-		"permanent_links": totalLinks - activeLinks.Int64 - expiredLinks.Int64,
+		"total_links":           totalLinks,
+		"total_accesses":        totalAccesses,
+		"active_links":          activeLinks,
+		"expired_links":         expiredLinks,
+		"permanent_links":       totalLinks - activeLinks - expiredLinks,
+		"disabled_links":        disabledLinks,
+		"deleted_links":         deletedLinks,
+		"counters_refreshed_at": refreshedAt.UTC(),
+		"expiring_within_24h":   expiringDay,
+		"expiring_within_7d":    expiringWeek,
 	}
 
 	return stats, nil
 }
+
+// ScanShortCodes 按 ID 升序分批读取短码
+func (r *Repository) ScanShortCodes(ctx context.Context, since time.Time, afterID int64, limit int) ([]string, int64, error) {
+	condition := "id > $1"
+	args := []interface{}{afterID, limit}
+	if !since.IsZero() {
+		condition += " AND created_at >= $3"
+		args = append(args, since)
+	}
+
+	query := `
+		SELECT id, short_code
+		FROM short_links
+		WHERE ` + condition + `
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, afterID, fmt.Errorf("failed to scan short codes: %w", err)
+	}
+	defer rows.Close()
+
+	lastID := afterID
+	codes := make([]string, 0, limit)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&lastID, &code); err != nil {
+			return nil, afterID, fmt.Errorf("failed to scan short code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, afterID, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return codes, lastID, nil
+}
+
+// scanShortLink 按 shortLinkColumns 的列顺序扫描一行
+func scanShortLink(row pgx.Row) (*models.ShortLink, error) {
+	shortLink := &models.ShortLink{}
+	err := row.Scan(
+		&shortLink.ID,
+		&shortLink.ShortCode,
+		&shortLink.OriginalURL,
+		&shortLink.AccessCount,
+		&shortLink.UniqueVisitors,
+		&shortLink.CreatedAt,
+		&shortLink.UpdatedAt,
+		&shortLink.ExpiresAt,
+		&shortLink.Status,
+		&shortLink.StatusReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return shortLink, nil
+}
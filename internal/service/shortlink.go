@@ -8,10 +8,13 @@ import (
 	"short-url/internal/config"
 	"short-url/internal/models"
 	"short-url/internal/utils"
+	"short-url/pkg/logger"
+	"sync"
+	"sync/atomic"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
@@ -19,39 +22,86 @@ var (
 	ErrShortCodeExists   = errors.New("short code already exists")
 	ErrExpiredLink       = errors.New("short link has expired")
 	ErrInvalidURL        = errors.New("invalid URL")
+	ErrBloomSyncRunning  = errors.New("bloom filter rebuild already running")
+	ErrInvalidUpdate     = errors.New("invalid update request")
+	ErrLinkDisabled      = errors.New("short link has been disabled")
+	ErrLinkDeleted       = errors.New("short link has been deleted")
+	ErrInvalidBatch      = errors.New("invalid batch request")
+	ErrInvalidQuery      = errors.New("invalid query")
 )
 
 type ShortLinkService struct {
-	repo        *Repository
-	cache       *cache.RedisClient
-	bloomFilter *cache.BloomFilter
-	encoder     *utils.Base62Encoder
-	config      *config.Config
-	logger      *zap.Logger
+	repo           ShortLinkStore
+	cache          cache.Cache
+	bloomFilter    *cache.BloomFilter
+	accessCounter  AccessCounter
+	visitorCounter VisitorCounter
+	encoder        *utils.Base62Encoder
+	config         *config.Config
+	logger         *zap.Logger
+	bloomSync      bloomSyncJob
+
+	bloomMonitor bloomMonitor
+
+	// invalidations 每次失效缓存时递增，回填缓存的查询据此发现自己读到的可能是旧数据
+	invalidations atomic.Uint64
+
+	loadGroup       singleflight.Group
+	redirectStats   RedirectStats
+	generationStats GenerationStats
+	statsCache      statsCache
+	clicks          chan *models.ClickEvent
+	stopCh          chan struct{}
+	stopOnce        sync.Once
+	workers         sync.WaitGroup
 }
 
 func NewShortLinkService(
-	repo *Repository,
-	cache *cache.RedisClient,
+	repo ShortLinkStore,
+	cache cache.Cache,
 	bloomFilter *cache.BloomFilter,
+	accessCounter AccessCounter,
+	visitorCounter VisitorCounter,
 	config *config.Config,
 	logger *zap.Logger,
 ) *ShortLinkService {
 	encoder := utils.NewBase62Encoder()
 	encoder.SetCodeLength(6) // 设置短码长度为6
 
+	// 点击事件缓冲区，未启用分析时为 nil
+	var clicks chan *models.ClickEvent
+	if config.Analytics.Enabled {
+		bufferSize := config.Analytics.BufferSize
+		if bufferSize <= 0 {
+			bufferSize = defaultClickBufferSize
+		}
+		clicks = make(chan *models.ClickEvent, bufferSize)
+	}
+
 	return &ShortLinkService{
-		repo:        repo,
-		cache:       cache,
-		bloomFilter: bloomFilter,
-		encoder:     encoder,
-		config:      config,
-		logger:      logger,
+		repo:           repo,
+		cache:          cache,
+		bloomFilter:    bloomFilter,
+		accessCounter:  accessCounter,
+		visitorCounter: visitorCounter,
+		encoder:        encoder,
+		config:         config,
+		logger:         logger,
+		stopCh:         make(chan struct{}),
+		clicks:         clicks,
 	}
 }
 
+// loggerFor 返回请求级日志器（带 request_id），后台任务没有时使用服务日志器
+func (s *ShortLinkService) loggerFor(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
 // CreateShortLink 创建短链接
 func (s *ShortLinkService) CreateShortLink(ctx context.Context, req *models.CreateShortLinkRequest) (*models.CreateShortLinkResponse, error) {
+	ctx, span := startSpan(ctx, "CreateShortLink")
+	defer span.End()
+
 	// 验证URL
 	if !utils.IsValidURL(req.URL) {
 		return nil, ErrInvalidURL
@@ -60,6 +110,15 @@ func (s *ShortLinkService) CreateShortLink(ctx context.Context, req *models.Crea
 	// 标准化URL
 	normalizedURL := utils.NormalizeURL(req.URL)
 
+	// 复用相同URL已有的短链接
+	if req.ReuseExisting && req.CustomCode == "" {
+		if existing := s.findReusableLink(ctx, normalizedURL); existing != nil {
+			response := s.newCreateResponse(existing)
+			response.Reused = true
+			return response, nil
+		}
+	}
+
 	// 生成短码
 	var shortCode string
 	var err error
@@ -94,58 +153,101 @@ func (s *ShortLinkService) CreateShortLink(ctx context.Context, req *models.Crea
 		ExpiresAt:   req.ExpiresAt,
 	}
 
-	// 保存到数据库
+	// 保存到数据库，检查之后被并发创建的短码由唯一约束兜底
 	if err := s.repo.CreateShortLink(ctx, shortLink); err != nil {
+		if errors.Is(err, ErrShortCodeExists) {
+			return nil, ErrShortCodeExists
+		}
 		return nil, fmt.Errorf("failed to save short link: %w", err)
 	}
 
 	// 添加到布隆过滤器
 	if err := s.bloomFilter.Add(ctx, shortCode); err != nil {
-		s.logger.Warn("failed to add to bloom filter", zap.Error(err))
+		s.loggerFor(ctx).Warn("failed to add to bloom filter", zap.Error(err))
 	}
 
-	// 缓存到Redis
-	if err := s.cache.SetWithDefaultTTL(ctx, s.cacheKey(shortCode), normalizedURL); err != nil {
-		s.logger.Warn("failed to cache short link", zap.Error(err))
+	// 其他实例的 L1 可能仍保存该短码的负缓存，写入前先删除并广播失效
+	s.invalidateLink(ctx, shortCode)
+	if err := s.cacheLink(ctx, shortLink); err != nil {
+		s.loggerFor(ctx).Warn("failed to cache short link", zap.Error(err))
 	}
 
-	// 构建响应
-	response := &models.CreateShortLinkResponse{
-		ShortURL:    s.buildShortURL(shortCode),
-		ShortCode:   shortCode,
-		OriginalURL: normalizedURL,
-		ExpiresAt:   req.ExpiresAt,
+	return s.newCreateResponse(shortLink), nil
+}
+
+// newCreateResponse 构建创建短链接响应
+func (s *ShortLinkService) newCreateResponse(shortLink *models.ShortLink) *models.CreateShortLinkResponse {
+	return &models.CreateShortLinkResponse{
+		ShortURL:    s.buildShortURL(shortLink.ShortCode),
+		ShortCode:   shortLink.ShortCode,
+		OriginalURL: shortLink.OriginalURL,
+		ExpiresAt:   shortLink.ExpiresAt,
 		CreatedAt:   shortLink.CreatedAt,
 	}
-
-	return response, nil
 }
 
 // GetOriginalURL 获取原始URL并重定向
 func (s *ShortLinkService) GetOriginalURL(ctx context.Context, shortCode string) (string, error) {
+	ctx, span := startSpan(ctx, "GetOriginalURL", shortCodeAttr(shortCode))
+	defer span.End()
+
 	// 首先检查缓存
-	originalURL, err := s.cache.Get(ctx, s.cacheKey(shortCode))
+	cached, err := s.getCachedLink(ctx, shortCode)
 	if err == nil {
-		// 异步增加访问计数
-		go func() {
-			if err := s.repo.IncrementAccessCount(context.Background(), shortCode); err != nil {
-				s.logger.Error("failed to increment access count", zap.Error(err))
-			}
-		}()
-		return originalURL, nil
+		if cached.Missing {
+			s.redirectStats.NegativeHits.Add(1)
+			return "", ErrShortCodeNotFound
+		}
+
+		s.redirectStats.CacheHits.Add(1)
+
+		if err := linkStatusError(cached.Status, cached.StatusReason); err != nil {
+			return "", err
+		}
+
+		// 缓存条目的 TTL 不会超过过期时间，这里再做一次校验以防时钟偏差
+		if cached.IsExpired() {
+			return "", ErrExpiredLink
+		}
+		s.recordAccess(ctx, shortCode)
+		return cached.OriginalURL, nil
 	}
 
 	// 缓存未命中，查询数据库
-	if err != redis.Nil {
-		s.logger.Warn("cache lookup error", zap.Error(err))
+	s.redirectStats.CacheMisses.Add(1)
+	if err != cache.ErrCacheMiss {
+		s.loggerFor(ctx).Warn("cache lookup error", zap.Error(err))
 	}
 
-	shortLink, err := s.repo.GetShortLinkByCode(ctx, shortCode)
+	// 布隆过滤器说不存在，且过滤器已完整装载时才确定不存在，无需查询数据库；
+	// 未装载的过滤器（如重启后的内存过滤器、被清空后重建的 Redis 键）交给数据库和负缓存判断
+	bloomPassed := false
+	if s.bloomFilterTrusted() {
+		exists, err := s.bloomFilter.Exists(ctx, shortCode)
+		if err != nil {
+			s.loggerFor(ctx).Warn("bloom filter check failed", zap.Error(err))
+		} else if !exists {
+			if s.bloomFilterPopulated(ctx) {
+				s.redirectStats.BloomRejects.Add(1)
+				return "", ErrShortCodeNotFound
+			}
+		} else {
+			bloomPassed = true
+			s.redirectStats.BloomPasses.Add(1)
+		}
+	}
+
+	shortLink, err := s.loadShortLink(ctx, shortCode)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return "", ErrShortCodeNotFound
+		if bloomPassed && errors.Is(err, ErrShortCodeNotFound) {
+			s.redirectStats.BloomFalsePositives.Add(1)
 		}
-		return "", fmt.Errorf("failed to get short link: %w", err)
+		return "", err
+	}
+
+	// 检查是否已停用或删除
+	if err := linkStatusError(shortLink.Status, shortLink.StatusReason); err != nil {
+		return "", err
 	}
 
 	// 检查是否过期
@@ -153,21 +255,72 @@ func (s *ShortLinkService) GetOriginalURL(ctx context.Context, shortCode string)
 		return "", ErrExpiredLink
 	}
 
-	// 更新缓存
-	if err := s.cache.SetWithDefaultTTL(ctx, s.cacheKey(shortCode), shortLink.OriginalURL); err != nil {
-		s.logger.Warn("failed to update cache", zap.Error(err))
+	// 增加访问计数
+	s.recordAccess(ctx, shortCode)
+
+	return shortLink.OriginalURL, nil
+}
+
+// loadShortLink 从数据库加载短链接并回填缓存，同一短码的并发未命中合并为一次查询
+func (s *ShortLinkService) loadShortLink(ctx context.Context, shortCode string) (*models.ShortLink, error) {
+	// 合并后的查询不应因首个调用方取消而让其他调用方一起失败
+	loadCtx := context.WithoutCancel(ctx)
+
+	// 只有实际执行查询的调用方会运行回调，据此区分合并请求中的发起者与跟随者
+	leader := false
+	result, err, shared := s.loadGroup.Do(shortCode, func() (interface{}, error) {
+		leader = true
+		s.redirectStats.DBLookups.Add(1)
+
+		generation := s.invalidations.Load()
+		defer s.dropStaleFill(loadCtx, shortCode, generation)
+
+		shortLink, err := s.repo.GetShortLinkByCode(loadCtx, shortCode)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				if err := s.cacheMissing(loadCtx, shortCode); err != nil {
+					s.loggerFor(ctx).Warn("failed to cache missing short code", zap.Error(err))
+				}
+				return nil, ErrShortCodeNotFound
+			}
+			return nil, fmt.Errorf("failed to get short link: %w", err)
+		}
+
+		// 更新缓存
+		if err := s.cacheLink(loadCtx, shortLink); err != nil {
+			s.loggerFor(ctx).Warn("failed to update cache", zap.Error(err))
+		}
+
+		return shortLink, nil
+	})
+	if shared && !leader {
+		s.redirectStats.Collapsed.Add(1)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// 增加访问计数
-	if err := s.repo.IncrementAccessCount(ctx, shortCode); err != nil {
-		s.logger.Error("failed to increment access count", zap.Error(err))
+	return result.(*models.ShortLink), nil
+}
+
+// recordAccess 将访问计入缓冲区，由后台任务批量写回；缓冲区不可用时直接写数据库
+func (s *ShortLinkService) recordAccess(ctx context.Context, shortCode string) {
+	err := s.accessCounter.Incr(ctx, shortCode)
+	if err == nil {
+		return
 	}
 
-	return shortLink.OriginalURL, nil
+	s.loggerFor(ctx).Warn("failed to buffer access count, writing through", zap.Error(err))
+	if err := s.repo.IncrementAccessCount(ctx, shortCode); err != nil {
+		s.loggerFor(ctx).Error("failed to increment access count", zap.Error(err))
+	}
 }
 
 // GetShortLinkInfo 获取短链接信息
 func (s *ShortLinkService) GetShortLinkInfo(ctx context.Context, shortCode string) (*models.ShortLinkInfo, error) {
+	ctx, span := startSpan(ctx, "GetShortLinkInfo", shortCodeAttr(shortCode))
+	defer span.End()
+
 	shortLink, err := s.repo.GetShortLinkByCode(ctx, shortCode)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -176,15 +329,177 @@ func (s *ShortLinkService) GetShortLinkInfo(ctx context.Context, shortCode strin
 		return nil, fmt.Errorf("failed to get short link: %w", err)
 	}
 
+	// 访问次数 = 已落库次数 + 缓冲区中尚未写回的增量
+	pending, err := s.accessCounter.Pending(ctx, shortCode)
+	if err != nil {
+		s.loggerFor(ctx).Warn("failed to get pending access count", zap.Error(err))
+	}
+
+	var uniqueVisitors *models.UniqueVisitorCounts
+	if s.visitorCounter != nil {
+		uniqueVisitors, err = s.uniqueVisitors(ctx, shortCode)
+		if err != nil {
+			s.loggerFor(ctx).Warn("failed to get unique visitors", zap.Error(err))
+		}
+	}
+
 	return &models.ShortLinkInfo{
-		ShortCode:   shortLink.ShortCode,
-		OriginalURL: shortLink.OriginalURL,
-		AccessCount: shortLink.AccessCount,
-		CreatedAt:   shortLink.CreatedAt,
-		ExpiresAt:   shortLink.ExpiresAt,
+		ShortCode:          shortLink.ShortCode,
+		OriginalURL:        shortLink.OriginalURL,
+		AccessCount:        shortLink.AccessCount + pending,
+		StoredAccessCount:  shortLink.AccessCount,
+		PendingAccessCount: pending,
+		Status:             shortLink.Status,
+		StatusReason:       shortLink.StatusReason,
+		UniqueVisitors:     uniqueVisitors,
+		CreatedAt:          shortLink.CreatedAt,
+		ExpiresAt:          shortLink.ExpiresAt,
 	}, nil
 }
 
+// UpdateShortLink 更新短链接的原始URL或过期时间，并使缓存失效
+func (s *ShortLinkService) UpdateShortLink(ctx context.Context, shortCode string, req *models.UpdateShortLinkRequest) (*models.ShortLinkInfo, error) {
+	ctx, span := startSpan(ctx, "UpdateShortLink", shortCodeAttr(shortCode))
+	defer span.End()
+
+	if req.URL == nil && req.ExpiresAt == nil && !req.ClearExpiry {
+		return nil, fmt.Errorf("%w: nothing to update", ErrInvalidUpdate)
+	}
+	if req.ExpiresAt != nil && req.ClearExpiry {
+		return nil, fmt.Errorf("%w: expires_at and clear_expires_at are mutually exclusive", ErrInvalidUpdate)
+	}
+
+	shortLink, err := s.repo.GetShortLinkByCode(ctx, shortCode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShortCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get short link: %w", err)
+	}
+	if shortLink.Status == models.LinkStatusDeleted {
+		return nil, ErrLinkDeleted
+	}
+
+	if req.URL != nil {
+		// 验证URL
+		if !utils.IsValidURL(*req.URL) {
+			return nil, ErrInvalidURL
+		}
+		shortLink.OriginalURL = utils.NormalizeURL(*req.URL)
+	}
+	if req.ExpiresAt != nil {
+		shortLink.ExpiresAt = req.ExpiresAt
+	}
+	if req.ClearExpiry {
+		shortLink.ExpiresAt = nil
+	}
+
+	// 读取之后短链接可能已被并发删除，更新语句同样排除已删除的短链接
+	if err := s.repo.UpdateShortLink(ctx, shortLink); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, s.missingOrDeleted(ctx, shortCode)
+		}
+		return nil, fmt.Errorf("failed to update short link: %w", err)
+	}
+
+	s.invalidateLink(ctx, shortCode)
+
+	return s.GetShortLinkInfo(ctx, shortCode)
+}
+
+// DeleteShortLink 软删除短链接，保留墓碑使短码不再被分配
+func (s *ShortLinkService) DeleteShortLink(ctx context.Context, shortCode string) (*models.ShortLinkInfo, error) {
+	ctx, span := startSpan(ctx, "DeleteShortLink", shortCodeAttr(shortCode))
+	defer span.End()
+
+	return s.setShortLinkStatus(ctx, shortCode, models.LinkStatusDeleted, "")
+}
+
+// DisableShortLink 停用短链接，停用后重定向返回 410
+func (s *ShortLinkService) DisableShortLink(ctx context.Context, shortCode, reason string) (*models.ShortLinkInfo, error) {
+	ctx, span := startSpan(ctx, "DisableShortLink", shortCodeAttr(shortCode))
+	defer span.End()
+
+	return s.setShortLinkStatus(ctx, shortCode, models.LinkStatusDisabled, reason)
+}
+
+// EnableShortLink 重新启用已停用的短链接
+func (s *ShortLinkService) EnableShortLink(ctx context.Context, shortCode string) (*models.ShortLinkInfo, error) {
+	ctx, span := startSpan(ctx, "EnableShortLink", shortCodeAttr(shortCode))
+	defer span.End()
+
+	return s.setShortLinkStatus(ctx, shortCode, models.LinkStatusActive, "")
+}
+
+// setShortLinkStatus 变更短链接状态并清除缓存
+func (s *ShortLinkService) setShortLinkStatus(ctx context.Context, shortCode, status, reason string) (*models.ShortLinkInfo, error) {
+	if err := s.repo.SetShortLinkStatus(ctx, shortCode, status, reason); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to set short link status: %w", err)
+		}
+
+		return nil, s.missingOrDeleted(ctx, shortCode)
+	}
+
+	s.invalidateLink(ctx, shortCode)
+
+	return s.GetShortLinkInfo(ctx, shortCode)
+}
+
+// missingOrDeleted 条件更新未命中任何行时，区分短码不存在与已删除
+func (s *ShortLinkService) missingOrDeleted(ctx context.Context, shortCode string) error {
+	exists, err := s.repo.ShortCodeExists(ctx, shortCode)
+	if err != nil {
+		return fmt.Errorf("failed to check short code existence: %w", err)
+	}
+	if exists {
+		return ErrLinkDeleted
+	}
+	return ErrShortCodeNotFound
+}
+
+// linkStatusError 将非正常状态转换为对应错误，停用原因附在错误信息中
+func linkStatusError(status, reason string) error {
+	var err error
+	switch status {
+	case models.LinkStatusDisabled:
+		err = ErrLinkDisabled
+	case models.LinkStatusDeleted:
+		err = ErrLinkDeleted
+	default:
+		return nil
+	}
+
+	if reason != "" {
+		return fmt.Errorf("%w: %s", err, reason)
+	}
+	return err
+}
+
+// invalidateLink 删除短链接的缓存条目，两级缓存会同时广播给其他实例
+func (s *ShortLinkService) invalidateLink(ctx context.Context, shortCode string) {
+	// 进行中的查询可能在修改提交前读到旧数据，并在删除之后才回填缓存。
+	// 递增计数让其回填后再删除一次（见 dropStaleFill）；Forget 只让之后的请求不再合并到该查询上
+	s.invalidations.Add(1)
+	s.loadGroup.Forget(shortCode)
+
+	s.deleteCachedLink(ctx, shortCode)
+}
+
+// dropStaleFill 查询期间发生过缓存失效时，删除本次可能用旧数据回填的缓存条目。
+// 只能覆盖本实例内的竞争，其他实例的回填最多存活 CACHE_TTL
+func (s *ShortLinkService) dropStaleFill(ctx context.Context, shortCode string, generation uint64) {
+	if s.invalidations.Load() != generation {
+		s.deleteCachedLink(ctx, shortCode)
+	}
+}
+
+func (s *ShortLinkService) deleteCachedLink(ctx context.Context, shortCode string) {
+	if err := s.cache.Delete(ctx, s.cacheKey(shortCode)); err != nil {
+		s.loggerFor(ctx).Error("failed to invalidate cache", zap.Error(err), zap.String("short_code", shortCode))
+	}
+}
+
 // generateUniqueShortCode 生成唯一的短码
 func (s *ShortLinkService) generateUniqueShortCode(ctx context.Context) (string, error) {
 	maxRetries := 10
@@ -196,33 +511,30 @@ func (s *ShortLinkService) generateUniqueShortCode(ctx context.Context) (string,
 			return "", err
 		}
 
-		// 使用布隆过滤器快速检查
+		// 使用布隆过滤器快速检查，只有可信且完整装载的过滤器说不存在才跳过数据库
 		exists, err := s.bloomFilter.Exists(ctx, shortCode)
 		if err != nil {
-			s.logger.Warn("bloom filter check failed", zap.Error(err))
-			// 如果布隆过滤器失败，直接检查数据库
-			dbExists, dbErr := s.repo.ShortCodeExists(ctx, shortCode)
-			if dbErr != nil {
-				return "", dbErr
-			}
-			if !dbExists {
-				return shortCode, nil
-			}
-		} else if !exists {
-			// 布隆过滤器说不存在，那就不存在
+			s.loggerFor(ctx).Warn("bloom filter check failed", zap.Error(err))
+		} else if !exists && s.bloomRejectionTrusted(ctx) {
 			return shortCode, nil
 		}
 
-		// 布隆过滤器说可能存在，需要进一步确认
+		// 布隆过滤器说可能存在或无法确定，查数据库确认
 		dbExists, err := s.repo.ShortCodeExists(ctx, shortCode)
 		if err != nil {
 			return "", err
 		}
 		if !dbExists {
+			if exists {
+				s.generationStats.BloomFalsePositives.Add(1)
+			}
 			return shortCode, nil
 		}
+
+		s.generationStats.Retries.Add(1)
 	}
 
+	s.generationStats.Failures.Add(1)
 	return "", fmt.Errorf("failed to generate unique short code after %d retries", maxRetries)
 }
 
@@ -231,17 +543,18 @@ func (s *ShortLinkService) isShortCodeExists(ctx context.Context, shortCode stri
 	// 首先检查布隆过滤器
 	exists, err := s.bloomFilter.Exists(ctx, shortCode)
 	if err != nil {
-		s.logger.Warn("bloom filter check failed", zap.Error(err))
+		s.loggerFor(ctx).Warn("bloom filter check failed", zap.Error(err))
 		// 布隆过滤器失败，直接查数据库
 		return s.repo.ShortCodeExists(ctx, shortCode)
 	}
 
-	if !exists {
-		// 布隆过滤器说不存在，那就确定不存在
+	// 过滤器可信且已完整装载时，"不存在"才是确定的；
+	// 重启后尚未装载或被清空的过滤器会漏掉已有短码
+	if !exists && s.bloomRejectionTrusted(ctx) {
 		return false, nil
 	}
 
-	// 布隆过滤器说可能存在，需要查数据库确认
+	// 布隆过滤器说可能存在或无法确定，需要查数据库确认
 	return s.repo.ShortCodeExists(ctx, shortCode)
 }
 
@@ -255,18 +568,21 @@ func (s *ShortLinkService) cacheKey(shortCode string) string {
 	return fmt.Sprintf("shorturl:%s", shortCode)
 }
 
-// GetStats 获取统计信息
-func (s *ShortLinkService) GetStats(ctx context.Context) (map[string]interface{}, error) {
-	return s.repo.GetStats(ctx)
-}
-
 // CleanExpiredLinks 清理过期链接
 func (s *ShortLinkService) CleanExpiredLinks(ctx context.Context) (int64, error) {
-	deletedCount, err := s.repo.DeleteExpiredLinks(ctx)
+	ctx, span := startSpan(ctx, "CleanExpiredLinks")
+	defer span.End()
+
+	shortCodes, err := s.repo.DeleteExpiredLinks(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	s.logger.Info("cleaned expired links", zap.Int64("count", deletedCount))
-	return deletedCount, nil
+	// 缓存中的条目仍是未删除状态，清除后重定向才会返回已删除
+	for _, shortCode := range shortCodes {
+		s.invalidateLink(ctx, shortCode)
+	}
+
+	s.loggerFor(ctx).Info("cleaned expired links", zap.Int("count", len(shortCodes)))
+	return int64(len(shortCodes)), nil
 }
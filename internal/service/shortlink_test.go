@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"short-url/internal/cache"
+	"short-url/internal/config"
+	"short-url/internal/models"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"go.uber.org/zap"
+)
+
+// newTestService 使用内存存储、内存缓存和内存布隆过滤器构造服务，过滤器尚未标记完整装载
+func newTestService(t *testing.T) (*ShortLinkService, *MemoryRepository, *cache.BloomFilter) {
+	t.Helper()
+
+	cfg := &config.Config{
+		App:   config.AppConfig{BaseURL: "http://localhost:8080"},
+		Cache: config.CacheConfig{TTL: time.Hour, NegativeTTL: time.Minute},
+		BloomFilter: config.BloomFilterConfig{
+			Backend:   cache.BloomBackendMemory,
+			Key:       "test",
+			Capacity:  1000,
+			ErrorRate: 0.01,
+		},
+	}
+
+	repo := NewMemoryRepository()
+	bloomFilter := cache.NewBloomFilter(nil, &cfg.BloomFilter)
+	if err := bloomFilter.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	svc := NewShortLinkService(repo, cache.NewLRUCache(&cfg.Cache), bloomFilter,
+		NewMemoryAccessCounter(), NewMemoryVisitorCounter(0), cfg, zap.NewNop())
+	return svc, repo, bloomFilter
+}
+
+func TestCreateShortLinkExistingCustomCode(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup 在已有短码 taken1 写入存储之后调整布隆过滤器
+		setup   func(t *testing.T, bf *cache.BloomFilter)
+		code    string
+		wantErr error
+	}{
+		{
+			name:    "unpopulated filter misses existing code",
+			setup:   func(t *testing.T, bf *cache.BloomFilter) {},
+			code:    "taken1",
+			wantErr: ErrShortCodeExists,
+		},
+		{
+			name: "populated filter misses existing code",
+			setup: func(t *testing.T, bf *cache.BloomFilter) {
+				if err := bf.MarkPopulated(context.Background()); err != nil {
+					t.Fatalf("MarkPopulated() error = %v", err)
+				}
+			},
+			code:    "taken1",
+			wantErr: ErrShortCodeExists,
+		},
+		{
+			name: "filter knows existing code",
+			setup: func(t *testing.T, bf *cache.BloomFilter) {
+				if err := bf.Add(context.Background(), "taken1"); err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+			},
+			code:    "taken1",
+			wantErr: ErrShortCodeExists,
+		},
+		{
+			name:  "free code",
+			setup: func(t *testing.T, bf *cache.BloomFilter) {},
+			code:  "fresh1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo, bf := newTestService(t)
+			// 直接写入存储，模拟过滤器被清空或重启前创建的短链接
+			if err := repo.CreateShortLink(ctx, &models.ShortLink{ShortCode: "taken1", OriginalURL: "https://example.com/taken"}); err != nil {
+				t.Fatalf("CreateShortLink() error = %v", err)
+			}
+			tt.setup(t, bf)
+
+			resp, err := svc.CreateShortLink(ctx, &models.CreateShortLinkRequest{URL: "https://example.com/new", CustomCode: tt.code})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateShortLink() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateShortLink() error = %v", err)
+			}
+			if resp.ShortCode != tt.code {
+				t.Errorf("ShortCode = %q, want %q", resp.ShortCode, tt.code)
+			}
+		})
+	}
+}
+
+func TestBatchCreateShortLinksExistingCustomCode(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestService(t)
+	if err := repo.CreateShortLink(ctx, &models.ShortLink{ShortCode: "taken1", OriginalURL: "https://example.com/taken"}); err != nil {
+		t.Fatalf("CreateShortLink() error = %v", err)
+	}
+
+	resp, err := svc.BatchCreateShortLinks(ctx, &models.BatchCreateShortLinkRequest{Items: []models.CreateShortLinkRequest{
+		{URL: "https://example.com/a", CustomCode: "taken1"},
+		{URL: "https://example.com/b", CustomCode: "fresh1"},
+		{URL: "https://example.com/c"},
+	}})
+	if err != nil {
+		t.Fatalf("BatchCreateShortLinks() error = %v", err)
+	}
+
+	if got := resp.Results[0].Error; got != ErrShortCodeExists.Error() {
+		t.Errorf("taken1 error = %q, want %q", got, ErrShortCodeExists.Error())
+	}
+	for _, i := range []int{1, 2} {
+		if resp.Results[i].Error != "" || resp.Results[i].Result == nil {
+			t.Errorf("item %d = %+v, want created", i, resp.Results[i])
+		}
+	}
+	if _, err := repo.GetShortLinkByCode(ctx, "fresh1"); err != nil {
+		t.Errorf("fresh1 not created: %v", err)
+	}
+	if resp.Succeeded != 2 || resp.Failed != 1 {
+		t.Errorf("Succeeded, Failed = %d, %d; want 2, 1", resp.Succeeded, resp.Failed)
+	}
+}
+
+// newTestRedisClient 启动进程内的 miniredis 并返回连接到它的客户端
+func newTestRedisClient(t *testing.T, cacheConfig *config.CacheConfig) *cache.RedisClient {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	host, port, err := net.SplitHostPort(server.Addr())
+	if err != nil {
+		t.Fatalf("SplitHostPort(%q) error = %v", server.Addr(), err)
+	}
+	portNumber, _ := strconv.Atoi(port)
+
+	redisClient := cache.NewRedisClient(&config.RedisConfig{Host: host, Port: portNumber}, cacheConfig)
+	t.Cleanup(func() { redisClient.Close() })
+	return redisClient
+}
+
+// newTieredTestServices 构造共享存储和 Redis 的两个服务实例，各自使用独立的 L1
+func newTieredTestServices(t *testing.T) (*ShortLinkService, *ShortLinkService) {
+	t.Helper()
+
+	first, repo, bloomFilter := newTestService(t)
+	redisClient := newTestRedisClient(t, &first.config.Cache)
+
+	services := []*ShortLinkService{first, NewShortLinkService(repo, nil, bloomFilter,
+		NewMemoryAccessCounter(), NewMemoryVisitorCounter(0), first.config, zap.NewNop())}
+	for _, svc := range services {
+		tiered, err := cache.NewTieredCache(redisClient, &svc.config.Cache)
+		if err != nil {
+			t.Fatalf("NewTieredCache() error = %v", err)
+		}
+		if err := tiered.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		t.Cleanup(func() { tiered.Close() })
+		svc.cache = tiered
+	}
+	return services[0], services[1]
+}
+
+func TestCreateShortLinkEvictsRemoteNegativeCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func(ctx context.Context, svc *ShortLinkService) error
+	}{
+		{
+			name: "single create",
+			create: func(ctx context.Context, svc *ShortLinkService) error {
+				_, err := svc.CreateShortLink(ctx, &models.CreateShortLinkRequest{URL: "https://example.com/new", CustomCode: "fresh1"})
+				return err
+			},
+		},
+		{
+			name: "batch create",
+			create: func(ctx context.Context, svc *ShortLinkService) error {
+				resp, err := svc.BatchCreateShortLinks(ctx, &models.BatchCreateShortLinkRequest{Items: []models.CreateShortLinkRequest{
+					{URL: "https://example.com/new", CustomCode: "fresh1"},
+				}})
+				if err == nil && resp.Failed > 0 {
+					err = errors.New(resp.Results[0].Error)
+				}
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			creator, reader := newTieredTestServices(t)
+
+			// 短码尚不存在，读取方在 L1 和 Redis 中写入负缓存
+			if _, err := reader.GetOriginalURL(ctx, "fresh1"); !errors.Is(err, ErrShortCodeNotFound) {
+				t.Fatalf("GetOriginalURL() error = %v, want %v", err, ErrShortCodeNotFound)
+			}
+			if err := tt.create(ctx, creator); err != nil {
+				t.Fatalf("create error = %v", err)
+			}
+
+			// 失效消息异步送达，读取方最终应不再命中负缓存
+			deadline := time.Now().Add(2 * time.Second)
+			for {
+				url, err := reader.GetOriginalURL(ctx, "fresh1")
+				if err == nil {
+					if url != "https://example.com/new" {
+						t.Errorf("GetOriginalURL() = %q, want https://example.com/new", url)
+					}
+					return
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("GetOriginalURL() error = %v after create", err)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestGetOriginalURLMissingCode(t *testing.T) {
+	const url = "https://example.com/stored"
+
+	// store 直接写入存储，绕过缓存和布隆过滤器
+	store := func(t *testing.T, repo *MemoryRepository) {
+		t.Helper()
+		if err := repo.CreateShortLink(context.Background(), &models.ShortLink{ShortCode: "code01", OriginalURL: url}); err != nil {
+			t.Fatalf("CreateShortLink() error = %v", err)
+		}
+	}
+	markPopulated := func(t *testing.T, bf *cache.BloomFilter) {
+		t.Helper()
+		if err := bf.MarkPopulated(context.Background()); err != nil {
+			t.Fatalf("MarkPopulated() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, svc *ShortLinkService, repo *MemoryRepository, bf *cache.BloomFilter)
+		// between 在两次查询之间执行
+		between          func(t *testing.T, repo *MemoryRepository)
+		wantURL          string
+		wantErr          error
+		wantDBLookups    int64
+		wantNegativeHits int64
+		wantBloomRejects int64
+	}{
+		{
+			name: "unpopulated filter falls back to the store",
+			setup: func(t *testing.T, svc *ShortLinkService, repo *MemoryRepository, bf *cache.BloomFilter) {
+				store(t, repo)
+			},
+			wantURL:       url,
+			wantDBLookups: 1,
+		},
+		{
+			name: "populated filter rejects without a lookup",
+			setup: func(t *testing.T, svc *ShortLinkService, repo *MemoryRepository, bf *cache.BloomFilter) {
+				markPopulated(t, bf)
+			},
+			wantErr:          ErrShortCodeNotFound,
+			wantBloomRejects: 2,
+		},
+		{
+			name: "untrusted filter falls back to the store",
+			setup: func(t *testing.T, svc *ShortLinkService, repo *MemoryRepository, bf *cache.BloomFilter) {
+				store(t, repo)
+				markPopulated(t, bf)
+				svc.setBloomFilterAvailable(false)
+			},
+			wantURL:       url,
+			wantDBLookups: 1,
+		},
+		{
+			// 负缓存只在 CACHE_NEGATIVE_TTL 内有效，直接写入存储的短码在此期间仍不可见
+			name:             "negative entry hides a code stored behind the service",
+			setup:            func(t *testing.T, svc *ShortLinkService, repo *MemoryRepository, bf *cache.BloomFilter) {},
+			between:          store,
+			wantErr:          ErrShortCodeNotFound,
+			wantDBLookups:    1,
+			wantNegativeHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo, bf := newTestService(t)
+			tt.setup(t, svc, repo, bf)
+
+			for i := 0; i < 2; i++ {
+				if i == 1 && tt.between != nil {
+					tt.between(t, repo)
+				}
+				got, err := svc.GetOriginalURL(ctx, "code01")
+				if !errors.Is(err, tt.wantErr) || got != tt.wantURL {
+					t.Fatalf("lookup %d: GetOriginalURL() = %q, %v; want %q, %v", i+1, got, err, tt.wantURL, tt.wantErr)
+				}
+			}
+
+			stats := svc.redirectStats.Snapshot()
+			if stats.DBLookups != tt.wantDBLookups || stats.NegativeHits != tt.wantNegativeHits || stats.BloomRejects != tt.wantBloomRejects {
+				t.Errorf("DBLookups, NegativeHits, BloomRejects = %d, %d, %d; want %d, %d, %d",
+					stats.DBLookups, stats.NegativeHits, stats.BloomRejects,
+					tt.wantDBLookups, tt.wantNegativeHits, tt.wantBloomRejects)
+			}
+		})
+	}
+}
+
+func TestShortLinkTombstones(t *testing.T) {
+	const url = "https://example.com/target"
+
+	tests := []struct {
+		name string
+		// change 在重定向已被缓存之后修改短链接
+		change          func(ctx context.Context, svc *ShortLinkService) error
+		wantRedirectErr error
+	}{
+		{
+			name:   "active",
+			change: func(ctx context.Context, svc *ShortLinkService) error { return nil },
+		},
+		{
+			name: "disabled",
+			change: func(ctx context.Context, svc *ShortLinkService) error {
+				_, err := svc.DisableShortLink(ctx, "code01", "abuse")
+				return err
+			},
+			wantRedirectErr: ErrLinkDisabled,
+		},
+		{
+			name: "disabled then enabled",
+			change: func(ctx context.Context, svc *ShortLinkService) error {
+				if _, err := svc.DisableShortLink(ctx, "code01", ""); err != nil {
+					return err
+				}
+				_, err := svc.EnableShortLink(ctx, "code01")
+				return err
+			},
+		},
+		{
+			name: "deleted",
+			change: func(ctx context.Context, svc *ShortLinkService) error {
+				_, err := svc.DeleteShortLink(ctx, "code01")
+				return err
+			},
+			wantRedirectErr: ErrLinkDeleted,
+		},
+		{
+			// 墓碑是终态，不能重新启用
+			name: "deleted then enabled",
+			change: func(ctx context.Context, svc *ShortLinkService) error {
+				if _, err := svc.DeleteShortLink(ctx, "code01"); err != nil {
+					return err
+				}
+				if _, err := svc.EnableShortLink(ctx, "code01"); !errors.Is(err, ErrLinkDeleted) {
+					return fmt.Errorf("EnableShortLink() error = %v, want %v", err, ErrLinkDeleted)
+				}
+				return nil
+			},
+			wantRedirectErr: ErrLinkDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, _, _ := newTestService(t)
+			if _, err := svc.CreateShortLink(ctx, &models.CreateShortLinkRequest{URL: url, CustomCode: "code01"}); err != nil {
+				t.Fatalf("CreateShortLink() error = %v", err)
+			}
+			if _, err := svc.GetOriginalURL(ctx, "code01"); err != nil {
+				t.Fatalf("GetOriginalURL() error = %v", err)
+			}
+
+			if err := tt.change(ctx, svc); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+
+			// 第一次读取缓存，第二次在清空缓存后读取存储，两条路径的结果应一致
+			for _, source := range []string{"cache", "store"} {
+				if source == "store" {
+					svc.deleteCachedLink(ctx, "code01")
+				}
+				got, err := svc.GetOriginalURL(ctx, "code01")
+				if !errors.Is(err, tt.wantRedirectErr) {
+					t.Fatalf("GetOriginalURL() from %s error = %v, want %v", source, err, tt.wantRedirectErr)
+				}
+				if err == nil && got != url {
+					t.Errorf("GetOriginalURL() from %s = %q, want %q", source, got, url)
+				}
+			}
+
+			// 停用和删除的短码都不会再被分配
+			_, err := svc.CreateShortLink(ctx, &models.CreateShortLinkRequest{URL: "https://example.com/other", CustomCode: "code01"})
+			if !errors.Is(err, ErrShortCodeExists) {
+				t.Errorf("CreateShortLink() reusing the code error = %v, want %v", err, ErrShortCodeExists)
+			}
+		})
+	}
+}
+
+// racingStore 在第一次读取短链接之后执行 race，模拟读取与写入之间提交的并发修改
+type racingStore struct {
+	*MemoryRepository
+	race func()
+}
+
+func (s *racingStore) GetShortLinkByCode(ctx context.Context, shortCode string) (*models.ShortLink, error) {
+	shortLink, err := s.MemoryRepository.GetShortLinkByCode(ctx, shortCode)
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+	return shortLink, err
+}
+
+func TestShortLinkConcurrentChanges(t *testing.T) {
+	const (
+		oldURL = "https://example.com/old"
+		newURL = "https://example.com/new"
+	)
+
+	update := func(ctx context.Context, svc *ShortLinkService) error {
+		url := newURL
+		_, err := svc.UpdateShortLink(ctx, "code01", &models.UpdateShortLinkRequest{URL: &url})
+		return err
+	}
+	remove := func(ctx context.Context, svc *ShortLinkService) error {
+		_, err := svc.DeleteShortLink(ctx, "code01")
+		return err
+	}
+	redirect := func(ctx context.Context, svc *ShortLinkService) error {
+		_, err := svc.GetOriginalURL(ctx, "code01")
+		return err
+	}
+
+	tests := []struct {
+		name string
+		// action 第一次读取短链接之后执行 race
+		action  func(ctx context.Context, svc *ShortLinkService) error
+		race    func(ctx context.Context, svc *ShortLinkService) error
+		wantErr error
+		// wantURL 和 wantRedirectErr 是之后重定向的结果
+		wantURL         string
+		wantRedirectErr error
+	}{
+		{
+			name:            "delete during update",
+			action:          update,
+			race:            remove,
+			wantErr:         ErrLinkDeleted,
+			wantRedirectErr: ErrLinkDeleted,
+		},
+		{
+			// 回填的旧数据由 dropStaleFill 删除
+			name:    "update during redirect load",
+			action:  redirect,
+			race:    update,
+			wantURL: newURL,
+		},
+		{
+			name:            "delete during redirect load",
+			action:          redirect,
+			race:            remove,
+			wantRedirectErr: ErrLinkDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo, _ := newTestService(t)
+			if err := repo.CreateShortLink(ctx, &models.ShortLink{ShortCode: "code01", OriginalURL: oldURL}); err != nil {
+				t.Fatalf("CreateShortLink() error = %v", err)
+			}
+
+			store := &racingStore{MemoryRepository: repo}
+			store.race = func() {
+				if err := tt.race(ctx, svc); err != nil {
+					t.Errorf("race error = %v", err)
+				}
+			}
+			svc.repo = store
+
+			if err := tt.action(ctx, svc); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("action error = %v, want %v", err, tt.wantErr)
+			}
+
+			got, err := svc.GetOriginalURL(ctx, "code01")
+			if !errors.Is(err, tt.wantRedirectErr) || got != tt.wantURL {
+				t.Errorf("GetOriginalURL() = %q, %v; want %q, %v", got, err, tt.wantURL, tt.wantRedirectErr)
+			}
+		})
+	}
+}
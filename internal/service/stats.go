@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/models"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultStatsDays            = 7
+	maxStatsDays                = 90
+	defaultStatsTopN            = 10
+	maxStatsTopN                = 100
+	defaultStatsCacheTTL        = time.Minute
+	defaultStatsRefreshInterval = 5 * time.Minute
+	statsRefreshTimeout         = 5 * time.Minute
+
+	// 即将过期统计的时间窗口
+	expiringSoonDay  = 24 * time.Hour
+	expiringSoonWeek = 7 * 24 * time.Hour
+)
+
+// statsCache 按查询参数缓存全局统计结果，并合并同一参数的并发计算
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+	group   singleflight.Group
+}
+
+type statsCacheEntry struct {
+	stats     map[string]interface{}
+	expiresAt time.Time
+}
+
+func (c *statsCache) get(key string) (map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+func (c *statsCache) set(key string, stats map[string]interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]statsCacheEntry)
+	}
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = statsCacheEntry{stats: stats, expiresAt: now.Add(ttl)}
+}
+
+// GetStats 获取全局计数、即将过期数以及最近若干天的每日新建数、点击数和点击最多的短链接。
+// 结果在进程内缓存 STATS_CACHE_TTL，返回的 map 在调用方之间共享，不可修改
+func (s *ShortLinkService) GetStats(ctx context.Context, req *models.StatsRequest) (map[string]interface{}, error) {
+	ctx, span := startSpan(ctx, "GetStats")
+	defer span.End()
+
+	days := req.Days
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidQuery, maxStatsDays)
+	}
+	topN := req.Top
+	if topN == 0 {
+		topN = defaultStatsTopN
+	}
+	if topN < 1 || topN > maxStatsTopN {
+		return nil, fmt.Errorf("%w: top must be between 1 and %d", ErrInvalidQuery, maxStatsTopN)
+	}
+
+	key := fmt.Sprintf("%d:%d", days, topN)
+	if stats, ok := s.statsCache.get(key); ok {
+		return stats, nil
+	}
+
+	// 合并后的查询不应因首个调用方取消而让其他调用方一起失败
+	loadCtx := context.WithoutCancel(ctx)
+	result, err, _ := s.statsCache.group.Do(key, func() (interface{}, error) {
+		stats, err := s.loadStats(loadCtx, days, topN)
+		if err != nil {
+			return nil, err
+		}
+
+		ttl := s.config.Stats.CacheTTL
+		if ttl <= 0 {
+			ttl = defaultStatsCacheTTL
+		}
+		s.statsCache.set(key, stats, ttl)
+		return stats, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]interface{}), nil
+}
+
+func (s *ShortLinkService) loadStats(ctx context.Context, days, topN int) (map[string]interface{}, error) {
+	stats, err := s.repo.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.visitorCounter != nil {
+		uniqueVisitors, err := s.uniqueVisitors(ctx, "")
+		if err != nil {
+			s.loggerFor(ctx).Warn("failed to get unique visitors", zap.Error(err))
+		} else {
+			stats["unique_visitors"] = uniqueVisitors
+		}
+	}
+
+	now := time.Now().UTC()
+	from := truncateToInterval(now, AnalyticsIntervalDay).AddDate(0, 0, -(days - 1))
+	window, err := s.repo.GetWindowStats(ctx, from, topN)
+	if err != nil {
+		return nil, err
+	}
+
+	window.From = from
+	window.To = now
+	window.Days = days
+	window.LinksCreated = fillDailyCounts(window.LinksCreated, from, days)
+	window.Clicks = fillDailyCounts(window.Clicks, from, days)
+	if window.TopLinks == nil {
+		window.TopLinks = []models.TopLink{}
+	}
+	stats["window"] = window
+
+	return stats, nil
+}
+
+// fillDailyCounts 补齐窗口内没有计数的日期
+func fillDailyCounts(counts []models.DailyCount, from time.Time, days int) []models.DailyCount {
+	byDay := make(map[time.Time]int64, len(counts))
+	for _, count := range counts {
+		byDay[count.Day] += count.Count
+	}
+
+	filled := make([]models.DailyCount, days)
+	for i := range filled {
+		day := from.AddDate(0, 0, i)
+		filled[i] = models.DailyCount{Day: day, Count: byDay[day]}
+	}
+	return filled
+}
+
+// runStatsRefresher 周期性刷新全局计数汇总和每日新建短链接数
+func (s *ShortLinkService) runStatsRefresher(interval time.Duration) {
+	defer s.workers.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.refreshStats()
+		}
+	}
+}
+
+func (s *ShortLinkService) refreshStats() {
+	ctx, cancel := context.WithTimeout(context.Background(), statsRefreshTimeout)
+	defer cancel()
+
+	until := time.Now().UTC()
+	for {
+		watermark, err := s.repo.RollupLinkCounts(ctx, until)
+		if err != nil {
+			s.logger.Error("failed to roll up link counts", zap.Error(err))
+			break
+		}
+		if watermark.IsZero() || !watermark.Before(until) {
+			break
+		}
+	}
+
+	if err := s.repo.RefreshStatsSummary(ctx); err != nil {
+		s.logger.Error("failed to refresh stats summary", zap.Error(err))
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"short-url/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// linkRollupName analytics_rollup_state 中每日新建短链接数的进度名称，同时用作 advisory lock 键
+	linkRollupName = "short_links"
+	// maxLinkRollupSpan 单次推进的最大时间跨度，首次汇总历史数据时分多个事务完成
+	maxLinkRollupSpan = 30 * 24 * time.Hour
+)
+
+// RefreshStatsSummary 刷新全局计数物化视图，其他实例正在刷新时跳过
+func (r *Repository) RefreshStatsSummary(ctx context.Context) error {
+	conn, err := r.db.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext('short_link_summary'))`).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to acquire stats summary lock: %w", err)
+	}
+	if !locked {
+		return nil
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtext('short_link_summary'))`)
+
+	if _, err := conn.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY short_link_summary`); err != nil {
+		return fmt.Errorf("failed to refresh stats summary: %w", err)
+	}
+
+	return nil
+}
+
+// RollupLinkCounts 重算 [watermark, end) 所涉及日期的新建短链接数并推进进度，单次最多推进 maxLinkRollupSpan
+func (r *Repository) RollupLinkCounts(ctx context.Context, until time.Time) (time.Time, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, linkRollupName).Scan(&locked); err != nil {
+		return time.Time{}, fmt.Errorf("failed to acquire link rollup lock: %w", err)
+	}
+	if !locked {
+		return time.Time{}, nil
+	}
+
+	var start time.Time
+	err = tx.QueryRow(ctx, `SELECT watermark FROM analytics_rollup_state WHERE name = $1 FOR UPDATE`, linkRollupName).Scan(&start)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// 首次汇总从最早创建的短链接开始
+		var earliest *time.Time
+		if err := tx.QueryRow(ctx, `SELECT MIN(created_at) FROM short_links`).Scan(&earliest); err != nil {
+			return time.Time{}, fmt.Errorf("failed to get earliest short link: %w", err)
+		}
+		start = until
+		if earliest != nil && earliest.Before(until) {
+			start = truncateToInterval(*earliest, AnalyticsIntervalDay)
+		}
+	case err != nil:
+		return time.Time{}, fmt.Errorf("failed to get link rollup watermark: %w", err)
+	}
+	start = start.UTC()
+
+	end := until
+	if end.Sub(start) > maxLinkRollupSpan {
+		end = start.Add(maxLinkRollupSpan)
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	if start.Before(end) {
+		query := `
+			INSERT INTO link_created_daily (day, links)
+			SELECT (created_at AT TIME ZONE 'UTC')::date, COUNT(*)
+			FROM short_links
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY 1
+			ON CONFLICT (day)
+			DO UPDATE SET links = EXCLUDED.links, updated_at = CURRENT_TIMESTAMP
+		`
+		if _, err := tx.Exec(ctx, query, truncateToInterval(start, AnalyticsIntervalDay), end); err != nil {
+			return time.Time{}, fmt.Errorf("failed to roll up link counts: %w", err)
+		}
+	}
+
+	stateQuery := `
+		INSERT INTO analytics_rollup_state (name, watermark)
+		VALUES ($1, $2)
+		ON CONFLICT (name)
+		DO UPDATE SET watermark = EXCLUDED.watermark, updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := tx.Exec(ctx, stateQuery, linkRollupName, end); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save link rollup watermark: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit link rollup: %w", err)
+	}
+
+	return end, nil
+}
+
+// GetWindowStats 每日新建数读取 link_created_daily，点击数读取天汇总表；两者汇总进度之后的部分读取原表
+func (r *Repository) GetWindowStats(ctx context.Context, from time.Time, topN int) (*models.StatsWindow, error) {
+	linkWatermark, err := r.rollupWatermark(ctx, linkRollupName)
+	if err != nil {
+		return nil, err
+	}
+	clickWatermark, err := r.rollupWatermark(ctx, clickRollupName)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &models.StatsWindow{}
+
+	createdQuery := `
+		SELECT day, SUM(links) FROM (
+			SELECT day::timestamp AT TIME ZONE 'UTC' AS day, links
+			FROM link_created_daily
+			WHERE day >= ($1 AT TIME ZONE 'UTC')::date
+			UNION ALL
+			SELECT date_trunc('day', created_at, 'UTC'), COUNT(*)
+			FROM short_links
+			WHERE created_at >= GREATEST($1, $2)
+			GROUP BY 1
+		) t
+		GROUP BY day
+		ORDER BY day
+	`
+	window.LinksCreated, err = r.dailyCounts(ctx, createdQuery, from, linkWatermark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily link counts: %w", err)
+	}
+
+	clicksQuery := `
+		SELECT day, SUM(clicks) FROM (
+			SELECT bucket AS day, clicks
+			FROM link_stats_daily
+			WHERE dimension = 'total' AND bucket >= $1
+			UNION ALL
+			SELECT date_trunc('day', clicked_at, 'UTC'), COUNT(*)
+			FROM click_events
+			WHERE clicked_at >= GREATEST($1, $2)
+			GROUP BY 1
+		) t
+		GROUP BY day
+		ORDER BY day
+	`
+	window.Clicks, err = r.dailyCounts(ctx, clicksQuery, from, clickWatermark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily click counts: %w", err)
+	}
+
+	topQuery := `
+		SELECT short_code, SUM(clicks) AS clicks FROM (
+			SELECT short_code, clicks
+			FROM link_stats_daily
+			WHERE dimension = 'total' AND bucket >= $1
+			UNION ALL
+			SELECT short_code, COUNT(*)
+			FROM click_events
+			WHERE clicked_at >= GREATEST($1, $2)
+			GROUP BY short_code
+		) t
+		GROUP BY short_code
+		ORDER BY clicks DESC, short_code
+		LIMIT $3
+	`
+	rows, err := r.db.Pool.Query(ctx, topQuery, from, clickWatermark, topN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link models.TopLink
+		if err := rows.Scan(&link.ShortCode, &link.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan top link: %w", err)
+		}
+		window.TopLinks = append(window.TopLinks, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return window, nil
+}
+
+// dailyCounts 执行返回 (day, count) 的查询，参数为窗口起点和汇总进度
+func (r *Repository) dailyCounts(ctx context.Context, query string, from, watermark time.Time) ([]models.DailyCount, error) {
+	rows, err := r.db.Pool.Query(ctx, query, from, watermark)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.DailyCount
+	for rows.Next() {
+		var count models.DailyCount
+		if err := rows.Scan(&count.Day, &count.Count); err != nil {
+			return nil, err
+		}
+		count.Day = count.Day.UTC()
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// rollupWatermark 读取汇总进度，尚未汇总过时返回零值
+func (r *Repository) rollupWatermark(ctx context.Context, name string) (time.Time, error) {
+	var watermark time.Time
+	err := r.db.Pool.QueryRow(ctx, `SELECT watermark FROM analytics_rollup_state WHERE name = $1`, name).Scan(&watermark)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get %s rollup watermark: %w", name, err)
+	}
+	return watermark.UTC(), nil
+}
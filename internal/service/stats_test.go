@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"testing"
+	"time"
+)
+
+// ctxCheckingStore 在上下文已取消时拒绝统计查询，模拟数据库驱动的行为
+type ctxCheckingStore struct {
+	*MemoryRepository
+}
+
+func (s ctxCheckingStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.MemoryRepository.GetStats(ctx)
+}
+
+func (s ctxCheckingStore) GetWindowStats(ctx context.Context, from time.Time, topN int) (*models.StatsWindow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.MemoryRepository.GetWindowStats(ctx, from, topN)
+}
+
+func TestGetStatsIgnoresCallerCancellation(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel bool
+	}{
+		{name: "live caller", cancel: false},
+		// 合并查询以首个调用方的上下文执行，它断开不应让其他调用方一起失败
+		{name: "cancelled caller", cancel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestService(t)
+			svc.repo = ctxCheckingStore{MemoryRepository: repo}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			stats, err := svc.GetStats(ctx, &models.StatsRequest{})
+			if err != nil {
+				t.Fatalf("GetStats() error = %v", err)
+			}
+			if stats == nil {
+				t.Fatal("GetStats() returned nil stats")
+			}
+		})
+	}
+}
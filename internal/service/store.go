@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"time"
+)
+
+// 存储后端类型
+const (
+	StoreDriverPostgres = "postgres"
+	StoreDriverMemory   = "memory"
+)
+
+// 列表排序字段
+const (
+	SortByCreatedAt   = "created_at"
+	SortByAccessCount = "access_count"
+)
+
+// 列表按过期情况筛选
+const (
+	ExpiryFilterActive    = "active"
+	ExpiryFilterExpired   = "expired"
+	ExpiryFilterPermanent = "permanent"
+)
+
+// ShortLinkFilter 短链接列表查询条件，结果按排序字段和 ID 降序排列，不包含已删除的短链接。
+// Expiry 按过期情况筛选，Status 按短链接状态（active 或 disabled）筛选
+type ShortLinkFilter struct {
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	Expiry         string
+	Status         string
+	Domain         string
+	MinAccessCount int64
+	SortBy         string
+	// After 上一页最后一条的位置，为 nil 时从第一页开始
+	After *ShortLinkCursor
+	Limit int
+}
+
+// ShortLinkCursor 键集分页位置，只使用与排序字段对应的值和 ID
+type ShortLinkCursor struct {
+	CreatedAt   time.Time `json:"c,omitempty"`
+	AccessCount int64     `json:"a,omitempty"`
+	ID          int64     `json:"i"`
+}
+
+// 分析时间粒度
+const (
+	AnalyticsIntervalHour = "hour"
+	AnalyticsIntervalDay  = "day"
+)
+
+// AnalyticsQuery 点击分析查询条件，时间范围为 [From, To)
+type AnalyticsQuery struct {
+	ShortCode string
+	From      time.Time
+	To        time.Time
+	Interval  string
+	// TopN 来源和设备分布各自返回的最大条目数
+	TopN int
+}
+
+// DailyVisitors 短码某一天（UTC）的独立访客数
+type DailyVisitors struct {
+	ShortCode string
+	Day       time.Time
+	Visitors  int64
+}
+
+// ShortLinkStore 短链接存储接口
+type ShortLinkStore interface {
+	// CreateShortLink 创建短链接，成功后回填 ID 与时间戳
+	CreateShortLink(ctx context.Context, shortLink *models.ShortLink) error
+	// CreateShortLinks 批量创建短链接，短码已存在的条目跳过而不报错，返回每条是否创建成功
+	CreateShortLinks(ctx context.Context, shortLinks []*models.ShortLink) ([]bool, error)
+	// GetShortLinkByCode 根据短码获取短链接，不存在时返回的错误包装 pgx.ErrNoRows
+	GetShortLinkByCode(ctx context.Context, shortCode string) (*models.ShortLink, error)
+	// UpdateShortLink 更新短链接的原始URL和过期时间，短链接不存在或已删除时返回的错误包装 pgx.ErrNoRows
+	UpdateShortLink(ctx context.Context, shortLink *models.ShortLink) error
+	// SetShortLinkStatus 设置短链接状态，短链接不存在或已删除时返回的错误包装 pgx.ErrNoRows
+	SetShortLinkStatus(ctx context.Context, shortCode, status, reason string) error
+	// ShortCodeExists 检查短码是否存在
+	ShortCodeExists(ctx context.Context, shortCode string) (bool, error)
+	// IncrementAccessCount 增加访问次数
+	IncrementAccessCount(ctx context.Context, shortCode string) error
+	// IncrementAccessCounts 批量累加多个短码的访问次数
+	IncrementAccessCounts(ctx context.Context, deltas map[string]int64) error
+	// GetShortLinksByTimeRange 根据时间范围获取短链接列表
+	GetShortLinksByTimeRange(ctx context.Context, start, end time.Time, limit, offset int) ([]*models.ShortLink, error)
+	// FindShortLinksByURL 按标准化后的原始URL反查短链接，不包含已删除的短链接
+	FindShortLinksByURL(ctx context.Context, originalURL string, limit int) ([]*models.ShortLink, error)
+	// ListShortLinks 按条件和键集分页查询短链接
+	ListShortLinks(ctx context.Context, filter ShortLinkFilter) ([]*models.ShortLink, error)
+	// DeleteExpiredLinks 将过期的短链接标记为已删除，返回被标记的短码
+	DeleteExpiredLinks(ctx context.Context) ([]string, error)
+	// GetStats 获取全局计数和即将过期的短链接数，计数可能来自定期刷新的汇总
+	GetStats(ctx context.Context) (map[string]interface{}, error)
+	// RefreshStatsSummary 刷新 GetStats 使用的全局计数汇总
+	RefreshStatsSummary(ctx context.Context) error
+	// RollupLinkCounts 将汇总进度到 until 之间创建的短链接累计到每日新建数，返回新的汇总进度；
+	// 其他实例正在汇总时返回零值
+	RollupLinkCounts(ctx context.Context, until time.Time) (time.Time, error)
+	// GetWindowStats 统计 from 之后每天的新建数、点击数和点击最多的 topN 个短链接，序列只包含计数非零的日期
+	GetWindowStats(ctx context.Context, from time.Time, topN int) (*models.StatsWindow, error)
+	// InsertClickEvents 批量写入点击事件
+	InsertClickEvents(ctx context.Context, events []*models.ClickEvent) error
+	// GetClickAnalytics 统计短链接在时间范围内的点击序列和分布，序列只包含有点击的分桶。
+	// 汇总进度之前的部分读取汇总表（按小时对齐），之后的部分读取原始点击事件
+	GetClickAnalytics(ctx context.Context, query AnalyticsQuery) (*models.LinkAnalytics, error)
+	// RollupClickStats 将汇总进度到 until 之间的点击事件汇总到小时和天汇总表，单次最多推进 maxClickRollupSpan，
+	// 返回新的汇总进度；其他实例正在汇总时返回零值
+	RollupClickStats(ctx context.Context, until time.Time) (time.Time, error)
+	// PruneClickEvents 删除 before 之前的原始点击事件，最多 limit 条，返回删除的条数
+	PruneClickEvents(ctx context.Context, before time.Time, limit int) (int64, error)
+	// SaveUniqueVisitors 写入按天的独立访客数，并更新短链接全部时间的独立访客数
+	SaveUniqueVisitors(ctx context.Context, daily []DailyVisitors, totals map[string]int64) error
+	// ScanShortCodes 按 ID 升序分批读取 afterID 之后、since 及之后创建的短码，返回本批最后一条的 ID；
+	// since 为零值时不限创建时间
+	ScanShortCodes(ctx context.Context, since time.Time, afterID int64, limit int) ([]string, int64, error)
+}
+
+var (
+	_ ShortLinkStore = (*Repository)(nil)
+	_ ShortLinkStore = (*MemoryRepository)(nil)
+)
+
+// APIKeyStore API key 存储接口，key 以 SHA-256 哈希保存
+type APIKeyStore interface {
+	// CreateAPIKey 保存 API key，成功后回填 ID 与创建时间
+	CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error
+	// AuthenticateAPIKey 查找未吊销的 API key 并更新最近使用时间，不存在或已吊销时返回的错误包装 pgx.ErrNoRows
+	AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	// RevokeAPIKey 吊销 API key，不存在或已吊销时返回的错误包装 pgx.ErrNoRows
+	RevokeAPIKey(ctx context.Context, id int64) error
+	// ListAPIKeys 按 ID 升序列出全部 API key，包括已吊销的
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+}
+
+var (
+	_ APIKeyStore = (*Repository)(nil)
+	_ APIKeyStore = (*MemoryRepository)(nil)
+)
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan 为服务方法创建 span，名称形如 ShortLinkService.GetOriginalURL
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "ShortLinkService."+method, trace.WithAttributes(attrs...))
+}
+
+// shortCodeAttr 短码 span 属性
+func shortCodeAttr(shortCode string) attribute.KeyValue {
+	return attribute.String("short_code", shortCode)
+}
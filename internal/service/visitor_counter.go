@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"short-url/internal/cache"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 独立访客计数后端类型
+const (
+	VisitorCounterRedis  = "redis"
+	VisitorCounterMemory = "memory"
+)
+
+const (
+	defaultVisitorTTL = 35 * 24 * time.Hour
+	visitorDayLayout  = "20060102"
+)
+
+// Visit 一次访问，VisitorID 为哈希后的访客指纹
+type Visit struct {
+	ShortCode string
+	VisitorID string
+	At        time.Time
+}
+
+// VisitorDay 有新访客的短码及日期（UTC）
+type VisitorDay struct {
+	ShortCode string
+	Day       time.Time
+}
+
+// VisitorCounter 按短码、按天统计独立访客数。shortCode 为空时表示全局统计
+type VisitorCounter interface {
+	// Add 记录一批访问
+	Add(ctx context.Context, visits []Visit) error
+	// Count 统计截至 day（含）的最近 days 天内的独立访客数
+	Count(ctx context.Context, shortCode string, day time.Time, days int) (int64, error)
+	// CountAll 统计全部时间的独立访客数
+	CountAll(ctx context.Context, shortCode string) (int64, error)
+	// DrainDirty 取出并清空自上次调用以来有新访客的短码和日期
+	DrainDirty(ctx context.Context) ([]VisitorDay, error)
+	// MarkDirty 汇总失败时将短码和日期放回待汇总集合
+	MarkDirty(ctx context.Context, days []VisitorDay) error
+}
+
+var (
+	_ VisitorCounter = (*RedisVisitorCounter)(nil)
+	_ VisitorCounter = (*MemoryVisitorCounter)(nil)
+)
+
+const (
+	visitorKeyPrefix = "uv:"
+	visitorGlobalKey = "global"
+	visitorDirtyKey  = "uv:dirty"
+)
+
+// redisDrainSetScript 读取并删除集合，保证多实例下每个成员只被取出一次
+var redisDrainSetScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+redis.call('DEL', KEYS[1])
+return members
+`)
+
+// RedisVisitorCounter 基于 Redis HyperLogLog 的独立访客计数。
+// 每个短码每天一个 HLL（uv:<code>:<yyyymmdd>，按 TTL 过期），另有不过期的全部时间 HLL（uv:<code>:all）；
+// 全局统计使用 global 作为短码。有新访客的短码和日期记录在 uv:dirty 集合中，供汇总任务读取
+type RedisVisitorCounter struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisVisitorCounter(redisClient *cache.RedisClient, ttl time.Duration) *RedisVisitorCounter {
+	if ttl <= 0 {
+		ttl = defaultVisitorTTL
+	}
+	return &RedisVisitorCounter{client: redisClient.GetClient(), ttl: ttl}
+}
+
+func (c *RedisVisitorCounter) Add(ctx context.Context, visits []Visit) error {
+	if len(visits) == 0 {
+		return nil
+	}
+
+	// 按键合并，每个 HLL 只发一条 PFADD
+	dayKeys := make(map[string][]interface{})
+	allKeys := make(map[string][]interface{})
+	dirty := make(map[string]struct{})
+	for _, visit := range visits {
+		day := visit.At.UTC().Format(visitorDayLayout)
+		for _, code := range []string{visit.ShortCode, visitorGlobalKey} {
+			dayKey := visitorKey(code, day)
+			dayKeys[dayKey] = append(dayKeys[dayKey], visit.VisitorID)
+			allKey := visitorKey(code, "all")
+			allKeys[allKey] = append(allKeys[allKey], visit.VisitorID)
+		}
+		dirty[visit.ShortCode+"|"+day] = struct{}{}
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, ids := range dayKeys {
+			pipe.PFAdd(ctx, key, ids...)
+			pipe.Expire(ctx, key, c.ttl)
+		}
+		for key, ids := range allKeys {
+			pipe.PFAdd(ctx, key, ids...)
+		}
+		members := make([]interface{}, 0, len(dirty))
+		for member := range dirty {
+			members = append(members, member)
+		}
+		pipe.SAdd(ctx, visitorDirtyKey, members...)
+		return nil
+	})
+	return err
+}
+
+func (c *RedisVisitorCounter) Count(ctx context.Context, shortCode string, day time.Time, days int) (int64, error) {
+	keys := make([]string, 0, days)
+	for i := 0; i < days; i++ {
+		keys = append(keys, visitorKey(visitorCode(shortCode), day.AddDate(0, 0, -i).UTC().Format(visitorDayLayout)))
+	}
+	return c.client.PFCount(ctx, keys...).Result()
+}
+
+func (c *RedisVisitorCounter) CountAll(ctx context.Context, shortCode string) (int64, error) {
+	return c.client.PFCount(ctx, visitorKey(visitorCode(shortCode), "all")).Result()
+}
+
+func (c *RedisVisitorCounter) DrainDirty(ctx context.Context) ([]VisitorDay, error) {
+	members, err := redisDrainSetScript.Run(ctx, c.client, []string{visitorDirtyKey}).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to drain dirty visitor counters: %w", err)
+	}
+
+	days := make([]VisitorDay, 0, len(members))
+	for _, member := range members {
+		shortCode, dayValue, ok := strings.Cut(member, "|")
+		if !ok {
+			continue
+		}
+		day, err := time.Parse(visitorDayLayout, dayValue)
+		if err != nil {
+			continue
+		}
+		days = append(days, VisitorDay{ShortCode: shortCode, Day: day})
+	}
+
+	return days, nil
+}
+
+func (c *RedisVisitorCounter) MarkDirty(ctx context.Context, days []VisitorDay) error {
+	if len(days) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, len(days))
+	for i, day := range days {
+		members[i] = day.ShortCode + "|" + day.Day.UTC().Format(visitorDayLayout)
+	}
+	return c.client.SAdd(ctx, visitorDirtyKey, members...).Err()
+}
+
+func visitorKey(code, suffix string) string {
+	return visitorKeyPrefix + code + ":" + suffix
+}
+
+func visitorCode(shortCode string) string {
+	if shortCode == "" {
+		return visitorGlobalKey
+	}
+	return shortCode
+}
+
+// MemoryVisitorCounter 进程内精确去重的访客计数，适用于单机演示和测试。
+// 按天的集合在超过 TTL 后于下次汇总时清理
+type MemoryVisitorCounter struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	days  map[string]map[string]struct{}
+	all   map[string]map[string]struct{}
+	dirty map[VisitorDay]struct{}
+}
+
+func NewMemoryVisitorCounter(ttl time.Duration) *MemoryVisitorCounter {
+	if ttl <= 0 {
+		ttl = defaultVisitorTTL
+	}
+	return &MemoryVisitorCounter{
+		ttl:   ttl,
+		days:  make(map[string]map[string]struct{}),
+		all:   make(map[string]map[string]struct{}),
+		dirty: make(map[VisitorDay]struct{}),
+	}
+}
+
+func (c *MemoryVisitorCounter) Add(ctx context.Context, visits []Visit) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, visit := range visits {
+		day := visit.At.UTC().Format(visitorDayLayout)
+		for _, code := range []string{visit.ShortCode, visitorGlobalKey} {
+			addVisitor(c.days, visitorKey(code, day), visit.VisitorID)
+			addVisitor(c.all, code, visit.VisitorID)
+		}
+		c.dirty[VisitorDay{ShortCode: visit.ShortCode, Day: truncateToInterval(visit.At, AnalyticsIntervalDay)}] = struct{}{}
+	}
+	return nil
+}
+
+func (c *MemoryVisitorCounter) Count(ctx context.Context, shortCode string, day time.Time, days int) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	visitors := make(map[string]struct{})
+	for i := 0; i < days; i++ {
+		key := visitorKey(visitorCode(shortCode), day.AddDate(0, 0, -i).UTC().Format(visitorDayLayout))
+		for id := range c.days[key] {
+			visitors[id] = struct{}{}
+		}
+	}
+	return int64(len(visitors)), nil
+}
+
+func (c *MemoryVisitorCounter) CountAll(ctx context.Context, shortCode string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(len(c.all[visitorCode(shortCode)])), nil
+}
+
+func (c *MemoryVisitorCounter) DrainDirty(ctx context.Context) ([]VisitorDay, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	days := make([]VisitorDay, 0, len(c.dirty))
+	for day := range c.dirty {
+		days = append(days, day)
+	}
+	c.dirty = make(map[VisitorDay]struct{})
+
+	// 清理超过 TTL 的按天集合
+	cutoff := time.Now().UTC().Add(-c.ttl).Format(visitorDayLayout)
+	for key := range c.days {
+		if key[strings.LastIndex(key, ":")+1:] < cutoff {
+			delete(c.days, key)
+		}
+	}
+
+	return days, nil
+}
+
+func (c *MemoryVisitorCounter) MarkDirty(ctx context.Context, days []VisitorDay) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, day := range days {
+		c.dirty[day] = struct{}{}
+	}
+	return nil
+}
+
+func addVisitor(sets map[string]map[string]struct{}, key, visitorID string) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(map[string]struct{})
+		sets[key] = set
+	}
+	set[visitorID] = struct{}{}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVisitorCounter(t *testing.T) {
+	backends := []struct {
+		name    string
+		counter func(t *testing.T) VisitorCounter
+	}{
+		{name: VisitorCounterMemory, counter: func(t *testing.T) VisitorCounter { return NewMemoryVisitorCounter(0) }},
+		{name: VisitorCounterRedis, counter: func(t *testing.T) VisitorCounter {
+			return NewRedisVisitorCounter(newTestRedisClient(t, nil), 0)
+		}},
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	visits := []Visit{
+		{ShortCode: "code01", VisitorID: "v1", At: today.Add(time.Hour)},
+		{ShortCode: "code01", VisitorID: "v1", At: today.Add(2 * time.Hour)},
+		{ShortCode: "code01", VisitorID: "v2", At: today.Add(3 * time.Hour)},
+		{ShortCode: "code01", VisitorID: "v3", At: yesterday.Add(time.Hour)},
+		{ShortCode: "code02", VisitorID: "v1", At: today.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		shortCode string
+		days      int
+		want      int64
+	}{
+		{name: "today", shortCode: "code01", days: 1, want: 2},
+		{name: "week", shortCode: "code01", days: 7, want: 3},
+		{name: "all time", shortCode: "code01", want: 3},
+		{name: "other link", shortCode: "code02", days: 1, want: 1},
+		{name: "global today", days: 1, want: 2},
+		{name: "global all time", want: 3},
+		{name: "unknown link", shortCode: "nope01", want: 0},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			counter := backend.counter(t)
+			if err := counter.Add(ctx, visits); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+
+			for _, tt := range tests {
+				var got int64
+				var err error
+				if tt.days > 0 {
+					got, err = counter.Count(ctx, tt.shortCode, today, tt.days)
+				} else {
+					got, err = counter.CountAll(ctx, tt.shortCode)
+				}
+				if err != nil || got != tt.want {
+					t.Errorf("%s: count = %d, %v; want %d", tt.name, got, err, tt.want)
+				}
+			}
+
+			wantDirty := []string{"code01|" + yesterday.Format(visitorDayLayout), "code01|" + today.Format(visitorDayLayout), "code02|" + today.Format(visitorDayLayout)}
+			if got := drainDirty(t, counter); !slices.Equal(got, wantDirty) {
+				t.Errorf("DrainDirty() = %v, want %v", got, wantDirty)
+			}
+			if got := drainDirty(t, counter); len(got) != 0 {
+				t.Errorf("second DrainDirty() = %v, want none", got)
+			}
+
+			// 汇总失败后放回的短码和日期会在下一次被取出
+			if err := counter.MarkDirty(ctx, []VisitorDay{{ShortCode: "code02", Day: today}}); err != nil {
+				t.Fatalf("MarkDirty() error = %v", err)
+			}
+			if got := drainDirty(t, counter); !slices.Equal(got, wantDirty[2:]) {
+				t.Errorf("DrainDirty() after MarkDirty = %v, want %v", got, wantDirty[2:])
+			}
+		})
+	}
+}
+
+// drainDirty 以 <code>|<yyyymmdd> 的有序列表返回 DrainDirty 的结果
+func drainDirty(t *testing.T, counter VisitorCounter) []string {
+	t.Helper()
+
+	days, err := counter.DrainDirty(context.Background())
+	if err != nil {
+		t.Fatalf("DrainDirty() error = %v", err)
+	}
+	members := make([]string, len(days))
+	for i, day := range days {
+		members[i] = day.ShortCode + "|" + day.Day.UTC().Format(visitorDayLayout)
+	}
+	slices.Sort(members)
+	return members
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"short-url/internal/models"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultVisitorRollupInterval = 5 * time.Minute
+	visitorRollupTimeout         = time.Minute
+)
+
+// uniqueVisitors 读取短码的独立访客数，shortCode 为空时为全局统计
+func (s *ShortLinkService) uniqueVisitors(ctx context.Context, shortCode string) (*models.UniqueVisitorCounts, error) {
+	today := time.Now().UTC()
+
+	day, err := s.visitorCounter.Count(ctx, shortCode, today, 1)
+	if err != nil {
+		return nil, err
+	}
+	week, err := s.visitorCounter.Count(ctx, shortCode, today, 7)
+	if err != nil {
+		return nil, err
+	}
+	allTime, err := s.visitorCounter.CountAll(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UniqueVisitorCounts{Day: day, Week: week, AllTime: allTime}, nil
+}
+
+// RollupUniqueVisitors 将有新访客的短码的当日和全部时间独立访客数写入数据库，失败时放回待汇总集合
+func (s *ShortLinkService) RollupUniqueVisitors(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "RollupUniqueVisitors")
+	defer span.End()
+
+	dirty, err := s.visitorCounter.DrainDirty(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(dirty) == 0 {
+		return 0, nil
+	}
+
+	restore := func() {
+		restoreCtx, cancel := context.WithTimeout(context.Background(), visitorRollupTimeout)
+		defer cancel()
+		if err := s.visitorCounter.MarkDirty(restoreCtx, dirty); err != nil {
+			s.loggerFor(ctx).Error("failed to restore dirty visitor counters", zap.Int("links", len(dirty)), zap.Error(err))
+		}
+	}
+
+	daily := make([]DailyVisitors, 0, len(dirty))
+	totals := make(map[string]int64)
+	for _, d := range dirty {
+		visitors, err := s.visitorCounter.Count(ctx, d.ShortCode, d.Day, 1)
+		if err != nil {
+			restore()
+			return 0, err
+		}
+		daily = append(daily, DailyVisitors{ShortCode: d.ShortCode, Day: d.Day, Visitors: visitors})
+
+		if _, ok := totals[d.ShortCode]; ok {
+			continue
+		}
+		total, err := s.visitorCounter.CountAll(ctx, d.ShortCode)
+		if err != nil {
+			restore()
+			return 0, err
+		}
+		totals[d.ShortCode] = total
+	}
+
+	if err := s.repo.SaveUniqueVisitors(ctx, daily, totals); err != nil {
+		restore()
+		return 0, err
+	}
+
+	return len(totals), nil
+}
+
+// runVisitorRollup 周期性汇总独立访客数，停止时做最后一次汇总
+func (s *ShortLinkService) runVisitorRollup(interval time.Duration) {
+	defer s.workers.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			s.rollupUniqueVisitors()
+			return
+		case <-ticker.C:
+			s.rollupUniqueVisitors()
+		}
+	}
+}
+
+func (s *ShortLinkService) rollupUniqueVisitors() {
+	ctx, cancel := context.WithTimeout(context.Background(), visitorRollupTimeout)
+	defer cancel()
+
+	links, err := s.RollupUniqueVisitors(ctx)
+	if err != nil {
+		s.logger.Error("failed to roll up unique visitors", zap.Error(err))
+		return
+	}
+	if links > 0 {
+		s.logger.Debug("rolled up unique visitors", zap.Int("links", links))
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+)
+
+// Start 启动服务的后台任务
+func (s *ShortLinkService) Start() {
+	s.prepareBloomFilter(context.Background())
+
+	if interval := s.config.BloomFilter.MonitorInterval; interval > 0 {
+		s.workers.Add(1)
+		go s.runBloomMonitor(interval)
+	}
+
+	interval := s.config.AccessCount.FlushInterval
+	if interval <= 0 {
+		interval = defaultAccessCountFlushInterval
+	}
+	s.workers.Add(1)
+	go s.runAccessCountFlusher(interval)
+
+	statsInterval := s.config.Stats.RefreshInterval
+	if statsInterval <= 0 {
+		statsInterval = defaultStatsRefreshInterval
+	}
+	s.workers.Add(1)
+	go s.runStatsRefresher(statsInterval)
+
+	if s.clicks != nil {
+		clickInterval := s.config.Analytics.FlushInterval
+		if clickInterval <= 0 {
+			clickInterval = defaultClickFlushInterval
+		}
+		batchSize := s.config.Analytics.BatchSize
+		if batchSize <= 0 {
+			batchSize = defaultClickBatchSize
+		}
+		s.workers.Add(1)
+		go s.runClickRecorder(clickInterval, batchSize)
+
+		rollupInterval := s.config.Analytics.RollupInterval
+		if rollupInterval <= 0 {
+			rollupInterval = defaultClickRollupInterval
+		}
+		s.workers.Add(1)
+		go s.runClickRollup(rollupInterval)
+	}
+
+	if s.visitorCounter != nil {
+		rollupInterval := s.config.Analytics.VisitorRollupInterval
+		if rollupInterval <= 0 {
+			rollupInterval = defaultVisitorRollupInterval
+		}
+		s.workers.Add(1)
+		go s.runVisitorRollup(rollupInterval)
+	}
+}
+
+// Shutdown 停止后台任务并等待其退出
+func (s *ShortLinkService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// untracedPaths 探针和指标抓取请求频繁且无业务意义，不创建 span
+var untracedPaths = map[string]bool{
+	"/health":       true,
+	"/health/live":  true,
+	"/health/ready": true,
+	"/metrics":      true,
+}
+
+// Middleware 为每个请求创建服务端 span，并从 traceparent 请求头延续上游调用链
+func Middleware(serviceName string) gin.HandlerFunc {
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !untracedPaths[r.URL.Path]
+	}))
+}
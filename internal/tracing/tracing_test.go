@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"short-url/internal/config"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newSpanRecorder 安装记录所有 span 的全局 TracerProvider 和 Setup 设置的传播器，测试结束后恢复
+func newSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	if _, err := Setup(context.Background(), &config.TracingConfig{Exporter: ExporterNone}, "test"); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	const (
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+		traceparent = "00-" + traceID + "-00f067aa0ba902b7-01"
+	)
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantSpans   int
+		wantTraceID string
+	}{
+		{name: "new trace", path: "/abc123", wantSpans: 1},
+		{name: "continues upstream trace", path: "/abc123", traceparent: traceparent, wantSpans: 1, wantTraceID: traceID},
+		{name: "readiness probe untraced", path: "/health/ready", wantSpans: 0},
+		{name: "metrics scrape untraced", path: "/metrics", wantSpans: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newSpanRecorder(t)
+			router := gin.New()
+			router.Use(Middleware(""))
+			router.GET("/:code", func(c *gin.Context) { c.Status(http.StatusFound) })
+			router.GET("/health/ready", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.GET("/metrics", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("spans = %d, want %d", len(spans), tt.wantSpans)
+			}
+			if tt.wantTraceID != "" {
+				if got := spans[0].SpanContext().TraceID().String(); got != tt.wantTraceID {
+					t.Errorf("trace ID = %s, want %s", got, tt.wantTraceID)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        func(ctx context.Context, client *redis.Client)
+		wantName   string
+		wantStatus codes.Code
+	}{
+		{
+			name:       "command",
+			run:        func(ctx context.Context, client *redis.Client) { client.Set(ctx, "key", "value", 0) },
+			wantName:   "redis SET",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "missing key is not an error",
+			run:        func(ctx context.Context, client *redis.Client) { client.Get(ctx, "missing") },
+			wantName:   "redis GET",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "failed command",
+			run:        func(ctx context.Context, client *redis.Client) { client.Do(ctx, "BF.ADD", "filter", "item") },
+			wantName:   "redis BF.ADD",
+			wantStatus: codes.Error,
+		},
+		{
+			name: "pipeline",
+			run: func(ctx context.Context, client *redis.Client) {
+				pipe := client.Pipeline()
+				pipe.Set(ctx, "key", "value", 0)
+				pipe.Get(ctx, "missing")
+				pipe.Exec(ctx)
+			},
+			wantName:   "redis pipeline",
+			wantStatus: codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newSpanRecorder(t)
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+			client.AddHook(RedisHook{})
+
+			tt.run(context.Background(), client)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("spans = %d, want 1", len(spans))
+			}
+			if spans[0].Name() != tt.wantName {
+				t.Errorf("span name = %q, want %q", spans[0].Name(), tt.wantName)
+			}
+			if spans[0].Status().Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", spans[0].Status().Code, tt.wantStatus)
+			}
+		})
+	}
+}
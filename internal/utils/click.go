@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// 设备类型
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+// DeviceClass 根据 User-Agent 粗略判断设备类型
+func DeviceClass(userAgent string) string {
+	if userAgent == "" {
+		return DeviceUnknown
+	}
+
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "bot"), strings.Contains(ua, "crawler"), strings.Contains(ua, "spider"),
+		strings.Contains(ua, "curl/"), strings.Contains(ua, "wget/"):
+		return DeviceBot
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "android"):
+		return DeviceMobile
+	default:
+		return DeviceDesktop
+	}
+}
+
+// ReferrerDomain 提取来源页面的小写主机名，无法解析时返回空字符串
+func ReferrerDomain(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+
+	parsedURL, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
+
+// VisitorFingerprint 由客户端 IP 和 User-Agent 计算访客指纹，不保存原始值
+func VisitorFingerprint(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(ip + "|" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// AnonymizeIP 抹去 IP 地址的主机部分：IPv4 保留 /24，IPv6 保留 /48
+func AnonymizeIP(ip string) string {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return ""
+	}
+
+	if ipv4 := parsedIP.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsedIP.Mask(net.CIDRMask(48, 128)).String()
+}
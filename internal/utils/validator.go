@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/sha256"
 	"net/url"
 	"strings"
 )
@@ -50,6 +51,9 @@ func NormalizeURL(rawURL string) string {
 		return rawURL
 	}
 
+	// 主机名不区分大小写
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+
 	// 移除尾随的斜杠（除非是根路径）
 	if len(parsedURL.Path) > 1 && strings.HasSuffix(parsedURL.Path, "/") {
 		parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
@@ -58,6 +62,12 @@ func NormalizeURL(rawURL string) string {
 	return parsedURL.String()
 }
 
+// HashURL 计算标准化 URL 的 SHA-256，用于反查和去重
+func HashURL(normalizedURL string) []byte {
+	sum := sha256.Sum256([]byte(normalizedURL))
+	return sum[:]
+}
+
 // IsValidShortCode 验证短码格式
 func IsValidShortCode(code string) bool {
 	if len(code) < 3 || len(code) > 20 {
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"short-url/internal/config"
 
 	"go.uber.org/zap"
@@ -35,3 +36,18 @@ func New(cfg *config.AppConfig) (*zap.Logger, error) {
 func NewNop() *zap.Logger {
 	return zap.NewNop()
 }
+
+type contextKey struct{}
+
+// NewContext 返回携带日志器的 context，用于传递请求级日志器
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext 返回 context 中的日志器，没有时返回 fallback
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return fallback
+}
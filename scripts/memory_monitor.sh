@@ -1,6 +1,8 @@
 #!/bin/bash
 
 # 内存监控脚本 - 监控Go应用内存使用情况
+# 运行时指标接口需要管理员令牌：ADMIN_TOKEN=xxx ./scripts/memory_monitor.sh
+# 调试接口单独监听内部地址时设置 DEBUG_URL，如 DEBUG_URL=http://127.0.0.1:6060
 
 echo "🔍 短链接服务内存监控"
 echo "监控间隔: 5秒"
@@ -19,14 +21,14 @@ while true; do
     DOCKER_MEM=$(docker stats --no-stream --format "{{.MemUsage}}" shorturl_app 2>/dev/null | cut -d'/' -f1 | sed 's/MiB//' | sed 's/GiB/*1024/' | bc 2>/dev/null)
     
     # 获取Go内存统计（如果接口可用）
-    MEMORY_STATS=$(curl -s http://localhost:8080/debug/memory 2>/dev/null)
+    MEMORY_STATS=$(curl -sf -H "Authorization: Bearer ${ADMIN_TOKEN}" "${DEBUG_URL:-http://localhost:8080}/debug/runtime" 2>/dev/null)
     
     if [ $? -eq 0 ] && [ -n "$MEMORY_STATS" ]; then
         # 如果内存接口可用，解析详细信息
-        HEAP_ALLOC=$(echo "$MEMORY_STATS" | jq -r '.data.before_gc.heap_alloc_mb // "N/A"' 2>/dev/null)
-        SYS_MEM=$(echo "$MEMORY_STATS" | jq -r '.data.before_gc.sys_mb // "N/A"' 2>/dev/null)
-        GOROUTINES=$(echo "$MEMORY_STATS" | jq -r '.data.before_gc.num_goroutine // "N/A"' 2>/dev/null)
-        GC_COUNT=$(echo "$MEMORY_STATS" | jq -r '.data.before_gc.num_gc // "N/A"' 2>/dev/null)
+        HEAP_ALLOC=$(echo "$MEMORY_STATS" | jq -r '.data.summary.heap_objects_bytes / 1048576 | floor' 2>/dev/null)
+        SYS_MEM=$(echo "$MEMORY_STATS" | jq -r '.data.summary.total_memory_bytes / 1048576 | floor' 2>/dev/null)
+        GOROUTINES=$(echo "$MEMORY_STATS" | jq -r '.data.summary.goroutines // "N/A"' 2>/dev/null)
+        GC_COUNT=$(echo "$MEMORY_STATS" | jq -r '.data.summary.gc_cycles // "N/A"' 2>/dev/null)
     else
         HEAP_ALLOC="N/A"
         SYS_MEM="N/A"
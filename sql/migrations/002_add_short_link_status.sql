@@ -0,0 +1,7 @@
+-- 短链接状态：active 正常，disabled 已停用（可恢复），deleted 已删除（墓碑，短码不再分配）
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS status_reason TEXT;
+
+ALTER TABLE short_links DROP CONSTRAINT IF EXISTS chk_short_links_status;
+ALTER TABLE short_links ADD CONSTRAINT chk_short_links_status
+    CHECK (status IN ('active', 'disabled', 'deleted'));
@@ -0,0 +1,11 @@
+-- 目标域名，从 original_url 中提取的小写主机名，供按域名筛选
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS destination_host TEXT
+    GENERATED ALWAYS AS (lower(substring(original_url FROM '^[^:/?#]+://(?:[^@/?#]*@)?([^:/?#]+)'))) STORED;
+
+-- 列表接口的键集分页索引，排除已删除的墓碑
+CREATE INDEX IF NOT EXISTS idx_short_links_list_created_at
+    ON short_links(created_at DESC, id DESC) WHERE status <> 'deleted';
+CREATE INDEX IF NOT EXISTS idx_short_links_list_access_count
+    ON short_links(access_count DESC, id DESC) WHERE status <> 'deleted';
+CREATE INDEX IF NOT EXISTS idx_short_links_destination_host
+    ON short_links(destination_host, created_at DESC) WHERE status <> 'deleted';
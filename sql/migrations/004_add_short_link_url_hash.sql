@@ -0,0 +1,6 @@
+-- 标准化后原始URL的 SHA-256，用于反查和去重。由应用写入，这里回填已有数据
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS url_hash BYTEA;
+
+UPDATE short_links SET url_hash = sha256(convert_to(original_url, 'UTF8')) WHERE url_hash IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_short_links_url_hash ON short_links(url_hash) WHERE status <> 'deleted';
@@ -0,0 +1,15 @@
+-- 点击事件：每次重定向一条记录，referrer_domain 与 device_class 在写入时计算
+CREATE TABLE IF NOT EXISTS click_events (
+    id BIGSERIAL PRIMARY KEY,
+    short_code VARCHAR(20) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL,
+    referrer TEXT,
+    referrer_domain TEXT,
+    user_agent TEXT,
+    device_class VARCHAR(16) NOT NULL,
+    client_ip TEXT,
+    accept_language TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_click_events_short_code_clicked_at ON click_events(short_code, clicked_at);
+CREATE INDEX IF NOT EXISTS idx_click_events_clicked_at ON click_events(clicked_at);
@@ -0,0 +1,11 @@
+-- 独立访客数：由 Redis HyperLogLog 周期性汇总而来
+CREATE TABLE IF NOT EXISTS link_unique_visitors (
+    short_code VARCHAR(20) NOT NULL,
+    day DATE NOT NULL,
+    visitors BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (short_code, day)
+);
+
+-- 全部时间的独立访客数
+ALTER TABLE short_links ADD COLUMN IF NOT EXISTS unique_visitors BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,28 @@
+-- 点击事件汇总表。dimension 为 total（key 为空）、referrer（key 为来源域名，空表示直接访问）或 device（key 为设备类型）
+CREATE TABLE IF NOT EXISTS link_stats_hourly (
+    short_code VARCHAR(20) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    dimension VARCHAR(16) NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (short_code, bucket, dimension, key)
+);
+
+CREATE TABLE IF NOT EXISTS link_stats_daily (
+    short_code VARCHAR(20) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    dimension VARCHAR(16) NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (short_code, bucket, dimension, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_stats_hourly_bucket ON link_stats_hourly(bucket);
+CREATE INDEX IF NOT EXISTS idx_link_stats_daily_bucket ON link_stats_daily(bucket);
+
+-- 汇总进度：watermark 之前的点击事件均已汇总
+CREATE TABLE IF NOT EXISTS analytics_rollup_state (
+    name TEXT PRIMARY KEY,
+    watermark TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
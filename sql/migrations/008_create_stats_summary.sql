@@ -0,0 +1,22 @@
+-- 全局计数的物化视图，由后台任务定期执行 REFRESH MATERIALIZED VIEW CONCURRENTLY 刷新，避免每次请求全表扫描
+CREATE MATERIALIZED VIEW IF NOT EXISTS short_link_summary AS
+SELECT
+    1 AS id,
+    COUNT(*) AS total_links,
+    COALESCE(SUM(access_count), 0) AS total_accesses,
+    COUNT(*) FILTER (WHERE expires_at IS NOT NULL AND expires_at > CURRENT_TIMESTAMP) AS active_links,
+    COUNT(*) FILTER (WHERE expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP) AS expired_links,
+    COUNT(*) FILTER (WHERE status = 'disabled') AS disabled_links,
+    COUNT(*) FILTER (WHERE status = 'deleted') AS deleted_links,
+    CURRENT_TIMESTAMP AS refreshed_at
+FROM short_links;
+
+-- CONCURRENTLY 刷新要求唯一索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_short_link_summary_id ON short_link_summary(id);
+
+-- 每天（UTC）创建的短链接数，由后台任务按 analytics_rollup_state 中 short_links 的进度增量维护
+CREATE TABLE IF NOT EXISTS link_created_daily (
+    day DATE PRIMARY KEY,
+    links BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);